@@ -0,0 +1,192 @@
+package goMicroServiceUtils
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*
+=================================================================================
+Archive Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// ErrZipSlip is returned by ExtractZip when an entry's path would land outside the destination directory,
+// e.g. "../../etc/passwd", or the entry is a symlink.
+var ErrZipSlip = errors.New("zip entry would be extracted outside the destination directory")
+
+// ErrZipTooLarge is returned by ExtractZip when the archive decompresses to more than the allowed total size.
+var ErrZipTooLarge = errors.New("zip archive is too large when decompressed")
+
+/*
+=================================================================================
+Archive Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// WriteZip streams a ZIP archive to the client as a download called filename, with one entry per item in
+// files, keyed by entry name and written in name order. Each entry is copied straight from its reader into
+// the response, so the archive is never held in memory. If a reader fails, its entry is left truncated and
+// the rest are still written; once the archive is complete, an error naming every failed entry is
+// returned. Entry names are cleaned so they can't point outside the archive when extracted.
+func (t *Tools) WriteZip(w http.ResponseWriter, filename string, files map[string]io.Reader) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", sanitizeDisplayName(filename)))
+	w.WriteHeader(http.StatusOK)
+
+	zw := zip.NewWriter(w)
+
+	var entryErrors []error
+	for _, name := range names {
+		entryName := zipEntryName(name)
+		if entryName == "" {
+			entryErrors = append(entryErrors, fmt.Errorf("zip entry %q: invalid name", name))
+			continue
+		}
+
+		entry, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     entryName,
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		})
+		if err != nil {
+			// The archive itself can no longer be written, e.g. because the client has gone away.
+			return err
+		}
+
+		_, err = io.Copy(entry, files[name])
+		if err != nil {
+			entryErrors = append(entryErrors, fmt.Errorf("zip entry %q: %w", name, err))
+		}
+	}
+
+	err := zw.Close()
+	if err != nil {
+		return err
+	}
+
+	return errors.Join(entryErrors...)
+}
+
+// zipEntryName cleans name into a relative, slash-separated path for use inside an archive, or returns ""
+// if nothing usable is left.
+func zipEntryName(name string) string {
+	cleaned := path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))
+	cleaned = strings.TrimPrefix(cleaned, "/")
+	if cleaned == "" || cleaned == "." {
+		return ""
+	}
+	return cleaned
+}
+
+// ExtractZip unzips the archive at zipPath into destDir, and returns the paths of the files it extracted. It
+// guards against malicious archives: an entry that would escape destDir (Zip Slip) returns an error wrapping
+// ErrZipSlip, and extraction stops with an error wrapping ErrZipTooLarge once more than maxTotalBytes have
+// been decompressed across all entries, counting the bytes actually written rather than trusting the sizes
+// the archive claims (zip bombs). On any error, the files already extracted are removed.
+func (t *Tools) ExtractZip(zipPath, destDir string, maxTotalBytes int64) ([]string, error) {
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening zip archive: %s", err.Error())
+	}
+	defer zr.Close()
+
+	err = t.CreateDirIfNotExist(destDir)
+	if err != nil {
+		return nil, err
+	}
+	root, err := filepath.Abs(destDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var extracted []string
+	fail := func(err error) ([]string, error) {
+		for _, p := range extracted {
+			_ = os.Remove(p)
+		}
+		return nil, err
+	}
+
+	var total int64
+	for _, f := range zr.File {
+		target := filepath.Join(root, filepath.FromSlash(f.Name))
+		if !strings.HasPrefix(target, root+string(os.PathSeparator)) || f.Mode()&os.ModeSymlink != 0 {
+			return fail(fmt.Errorf("%w: %q", ErrZipSlip, f.Name))
+		}
+
+		if f.FileInfo().IsDir() {
+			err = t.CreateDirIfNotExist(target)
+			if err != nil {
+				return fail(err)
+			}
+			continue
+		}
+
+		written, err := extractZipFile(f, target, maxTotalBytes-total)
+		if written >= 0 {
+			extracted = append(extracted, target)
+		}
+		if err != nil {
+			return fail(err)
+		}
+		total += written
+	}
+
+	return extracted, nil
+}
+
+// extractZipFile writes the entry f to target, refusing to write more than remaining bytes. It returns the
+// number of bytes written, or -1 if target was never created.
+func extractZipFile(f *zip.File, target string, remaining int64) (int64, error) {
+	if f.UncompressedSize64 > uint64(remaining) {
+		return -1, fmt.Errorf("%w: entry %q", ErrZipTooLarge, f.Name)
+	}
+
+	err := os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return -1, err
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return -1, fmt.Errorf("error reading zip entry %q: %s", f.Name, err.Error())
+	}
+	defer rc.Close()
+
+	outfile, err := os.Create(target)
+	if err != nil {
+		return -1, err
+	}
+	defer outfile.Close()
+
+	// Copy at most one byte more than is allowed, which is enough to tell the entry lied about its size.
+	written, err := io.Copy(outfile, io.LimitReader(rc, remaining+1))
+	if err != nil {
+		return written, fmt.Errorf("error reading zip entry %q: %s", f.Name, err.Error())
+	}
+	if written > remaining {
+		return written, fmt.Errorf("%w: entry %q", ErrZipTooLarge, f.Name)
+	}
+
+	return written, nil
+}
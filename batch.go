@@ -0,0 +1,54 @@
+package goMicroServiceUtils
+
+import (
+	"fmt"
+	"net/http"
+)
+
+/*
+=================================================================================
+Batch Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// BatchResult is the outcome of processing one item of a batch request, for WriteBatchJSON.
+type BatchResult struct {
+	Index   int         `json:"index"`           // position of the item in the request
+	Success bool        `json:"success"`         // whether the item was processed successfully
+	Error   string      `json:"error,omitempty"` // what went wrong, when Success is false
+	Data    interface{} `json:"data,omitempty"`  // the item's result, when Success is true
+}
+
+/*
+=================================================================================
+Batch Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// WriteBatchJSON sends the results of a batch request as a 207 Multi-Status JSON response, so clients can see
+// which items succeeded and which failed. The results go in the Data field of the payload; Error is set if
+// any item failed, and Message summarises how many succeeded.
+func (t *Tools) WriteBatchJSON(w http.ResponseWriter, results []BatchResult) error {
+	succeeded := 0
+	for _, result := range results {
+		if result.Success {
+			succeeded++
+		}
+	}
+
+	// Send an empty list rather than null, so clients can always iterate over the results.
+	if results == nil {
+		results = []BatchResult{}
+	}
+
+	var payload JSONResponse
+	payload.Error = succeeded < len(results)
+	payload.Message = fmt.Sprintf("%d of %d items succeeded", succeeded, len(results))
+	payload.Data = results
+
+	return t.WriteJSON(w, http.StatusMultiStatus, payload)
+}
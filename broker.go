@@ -0,0 +1,206 @@
+package goMicroServiceUtils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+/*
+=================================================================================
+Broker Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// BrokerHandlerFunc handles one broker action. It is given the request's auth details and the raw JSON body,
+// which it can unmarshal into whatever type the action expects, and returns the data to send back.
+type BrokerHandlerFunc func(auth AuthPayload, raw json.RawMessage) (interface{}, error)
+
+// BrokerRouter is an http.Handler that reads a BrokerRequestPayload and dispatches it to the handler
+// registered for its Action. Create one with Tools.NewBrokerRouter.
+type BrokerRouter struct {
+	tools *Tools
+
+	mu       sync.RWMutex
+	handlers map[string]BrokerHandlerFunc
+}
+
+/*
+=================================================================================
+Broker Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// DecodeByAction reads a JSON body whose shape depends on its "action" field, in the style of
+// BrokerRequestPayload. The action is read first and looked up in registry, which maps each action to a
+// function returning a pointer to a new value of the matching concrete type; the whole body is then decoded
+// into that value with the same rules and error messages as ReadJSON. Types that don't declare an action
+// field of their own, like LogPayload, are given the body without it, so they aren't rejected for having an
+// unknown field. It returns the action and the decoded value, so a handler can switch on the value's type:
+//
+//	action, payload, err := t.DecodeByAction(r, map[string]func() interface{}{
+//		"auth": func() interface{} { return &BrokerRequestPayload{} },
+//		"log":  func() interface{} { return &LogPayload{} },
+//	})
+//	if err != nil {
+//		t.ErrorJSON(w, err)
+//		return
+//	}
+//	switch p := payload.(type) {
+//	case *BrokerRequestPayload:
+//		// authenticate using p.Auth
+//	case *LogPayload:
+//		// write the log entry
+//	}
+func (t *Tools) DecodeByAction(r *http.Request, registry map[string]func() interface{}) (string, interface{}, error) {
+	body, err := t.readJSONBody(r)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var envelope struct {
+		Action string `json:"action"`
+	}
+	err = json.Unmarshal(body, &envelope)
+	if err != nil {
+		return "", nil, errors.New("body must be a JSON object with a string action field")
+	}
+	if envelope.Action == "" {
+		return "", nil, errors.New("body must contain an action")
+	}
+
+	newPayload, ok := registry[envelope.Action]
+	if !ok {
+		return envelope.Action, nil, fmt.Errorf("unknown action %q", envelope.Action)
+	}
+	payload := newPayload()
+
+	if !declaresJSONField(payload, "action") {
+		body, err = withoutJSONField(body, "action")
+		if err != nil {
+			return envelope.Action, nil, errors.New("body must be a JSON object with a string action field")
+		}
+	}
+
+	// The body has already been read, so hand ReadJSON a fresh copy of it.
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	err = t.ReadJSON(nil, r, payload)
+	if err != nil {
+		return envelope.Action, nil, err
+	}
+
+	return envelope.Action, payload, nil
+}
+
+// NewBrokerRouter returns an empty BrokerRouter, which uses t to read requests and write responses.
+func (t *Tools) NewBrokerRouter() *BrokerRouter {
+	return &BrokerRouter{
+		tools:    t,
+		handlers: make(map[string]BrokerHandlerFunc),
+	}
+}
+
+// Handle registers fn as the handler for action. Registering the same action again replaces its handler.
+func (b *BrokerRouter) Handle(action string, fn BrokerHandlerFunc) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers[action] = fn
+}
+
+// ServeHTTP reads a BrokerRequestPayload and calls the handler registered for its Action, sending the result
+// back as the Data of a JSONResponse. Bad bodies and unknown actions get a 400, the latter listing the valid
+// actions; an error from the handler is sent with ErrorJSONAuto, so its status can be set with
+// RegisterErrorStatus.
+func (b *BrokerRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := b.tools.readJSONBody(r)
+	if err != nil {
+		_ = b.tools.ErrorJSON(w, err)
+		return
+	}
+
+	var payload BrokerRequestPayload
+	err = json.Unmarshal(body, &payload)
+	if err != nil {
+		_ = b.tools.ErrorJSON(w, errors.New("body must be a JSON object with a string action field"))
+		return
+	}
+
+	b.mu.RLock()
+	fn, ok := b.handlers[payload.Action]
+	b.mu.RUnlock()
+	if !ok {
+		_ = b.tools.ErrorJSON(w, fmt.Errorf("unknown action %q, valid actions are: %s", payload.Action, strings.Join(b.actions(), ", ")))
+		return
+	}
+
+	data, err := fn(payload.Auth, json.RawMessage(body))
+	if err != nil {
+		_ = b.tools.ErrorJSONAuto(w, err)
+		return
+	}
+
+	_ = b.tools.WriteJSON(w, http.StatusOK, JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("action %s completed", payload.Action),
+		Data:    data,
+	})
+}
+
+// actions returns the registered actions, sorted so error messages are stable.
+func (b *BrokerRouter) actions() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	actions := make([]string, 0, len(b.handlers))
+	for action := range b.handlers {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	return actions
+}
+
+// declaresJSONField reports whether the struct v points to has a field that the JSON key name decodes into.
+// Anything other than a struct, such as a map, is assumed to accept every key.
+func declaresJSONField(v interface{}, name string) bool {
+	typ := reflect.TypeOf(v)
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return true
+	}
+
+	_, ok := jsonFieldTypes(typ)[strings.ToLower(name)]
+	return ok
+}
+
+// withoutJSONField returns the JSON object body with the key name removed, matching it case-insensitively as
+// encoding/json does.
+func withoutJSONField(body []byte, name string) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(body, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	for key := range fields {
+		if strings.EqualFold(key, name) {
+			delete(fields, key)
+		}
+	}
+
+	return json.Marshal(fields)
+}
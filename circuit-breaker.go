@@ -0,0 +1,136 @@
+package goMicroServiceUtils
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+/*
+=================================================================================
+Circuit Breaker Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// ErrCircuitOpen is returned by Breaker.Do, without calling the wrapped function, while the breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// breakerState is the state a Breaker is currently in.
+type breakerState int
+
+const (
+	breakerClosed   breakerState = iota // calls pass through, failures are counted
+	breakerOpen                         // calls are rejected with ErrCircuitOpen
+	breakerHalfOpen                     // a single probe call is allowed through to test the dependency
+)
+
+// Breaker is a lightweight circuit breaker for calls to other services. Create one with Tools.NewBreaker.
+type Breaker struct {
+	failureThreshold int           // consecutive failures before the breaker opens
+	resetTimeout     time.Duration // how long the breaker stays open before allowing a probe
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+/*
+=================================================================================
+Circuit Breaker Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// NewBreaker returns a closed Breaker that opens after failureThreshold consecutive failures and moves to
+// half-open once resetTimeout has passed.
+func (t *Tools) NewBreaker(failureThreshold int, resetTimeout time.Duration) *Breaker {
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+
+	return &Breaker{
+		failureThreshold: failureThreshold,
+		resetTimeout:     resetTimeout,
+	}
+}
+
+// Do calls fn if the breaker allows it, and records whether it failed. While the breaker is open, or while a
+// half-open probe is already in flight, fn is not called and ErrCircuitOpen is returned. A successful probe
+// closes the breaker again; a failed one re-opens it for another resetTimeout.
+//
+// Calls to other services can be wrapped like so, treating 5xx responses as failures:
+//
+//	err := b.Do(func() error {
+//		resp, status, err = t.PushJSONToRemote(uri, data)
+//		if err == nil && status >= http.StatusInternalServerError {
+//			return fmt.Errorf("remote service returned status %d", status)
+//		}
+//		return err
+//	})
+func (b *Breaker) Do(fn func() error) error {
+	if !b.allow() {
+		return ErrCircuitOpen
+	}
+
+	// A panicking call still counts as a failure, otherwise a half-open breaker would wait on its probe forever.
+	defer func() {
+		if p := recover(); p != nil {
+			b.record(errors.New("call panicked"))
+			panic(p)
+		}
+	}()
+
+	err := fn()
+	b.record(err)
+
+	return err
+}
+
+// allow reports whether a call may go ahead, moving an expired open breaker into the half-open state and
+// claiming the single probe slot when half-open.
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerOpen && time.Since(b.openedAt) >= b.resetTimeout {
+		b.state = breakerHalfOpen
+		b.probing = false
+	}
+
+	switch b.state {
+	case breakerOpen:
+		return false
+	case breakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+	}
+
+	return true
+}
+
+// record updates the breaker's state with the outcome of a call that was allowed through.
+func (b *Breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		b.state = breakerClosed
+		b.failures = 0
+		b.probing = false
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.failureThreshold {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+		b.probing = false
+	}
+}
@@ -0,0 +1,145 @@
+package goMicroServiceUtils
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+/*
+=================================================================================
+Client IP Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// parsedProxies caches TrustedProxies parsed into networks, along with the entries they were parsed from.
+type parsedProxies struct {
+	source   []string
+	networks []*net.IPNet
+}
+
+/*
+=================================================================================
+Client IP Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// ClientIP returns the IP address of the client making the request, for middleware such as RateLimit and
+// LogRequests. Forwarding headers are only believed when the request comes from a trusted proxy, i.e. its
+// RemoteAddr is in TrustedProxies. X-Forwarded-For is then read from right to left, skipping trusted
+// proxies, and the first untrusted address is the client; an address a client put at the left of the
+// header is therefore never used in preference to one a trusted proxy added. X-Real-IP is used when there
+// is no X-Forwarded-For. Headers on requests from anywhere else are ignored, so they can't be spoofed, and
+// the RemoteAddr host is returned.
+func (t *Tools) ClientIP(r *http.Request) string {
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+
+	trusted := t.trustedProxies()
+	if !trusted(remote) {
+		return remote
+	}
+
+	// There may be several X-Forwarded-For headers, which together make up one list.
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+
+	client := ""
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			// Anything left of a garbled entry can't be trusted.
+			break
+		}
+		client = ip.String()
+		if !trusted(client) {
+			return client
+		}
+	}
+	if client != "" {
+		return client
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+
+	return remote
+}
+
+// trustedProxies returns a function reporting whether an IP address belongs to one of TrustedProxies. When
+// TrustedProxies is empty, every address is trusted if TrustProxy is set, and none otherwise. The entries are
+// parsed once and cached until TrustedProxies changes; malformed entries are logged with slog.Default() when
+// they are parsed, and otherwise ignored.
+func (t *Tools) trustedProxies() func(ip string) bool {
+	if len(t.TrustedProxies) == 0 {
+		return func(string) bool { return t.TrustProxy }
+	}
+
+	networks := t.trustedNetworks()
+
+	return func(ip string) bool {
+		parsed := net.ParseIP(ip)
+		if parsed == nil {
+			return false
+		}
+		for _, network := range networks {
+			if network.Contains(parsed) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// trustedNetworks returns TrustedProxies parsed into networks, re-parsing them only if the slice has changed
+// since they were last parsed.
+func (t *Tools) trustedNetworks() []*net.IPNet {
+	t.mu.RLock()
+	cached := t.proxies
+	t.mu.RUnlock()
+	if cached != nil && slices.Equal(cached.source, t.TrustedProxies) {
+		return cached.networks
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Another request may have parsed them while we waited for the lock.
+	if t.proxies != nil && slices.Equal(t.proxies.source, t.TrustedProxies) {
+		return t.proxies.networks
+	}
+
+	var networks []*net.IPNet
+	for _, proxy := range t.TrustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil {
+				bits := 8 * net.IPv6len
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 8*net.IPv4len
+				}
+				networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+		} else if _, network, err := net.ParseCIDR(proxy); err == nil {
+			networks = append(networks, network)
+			continue
+		}
+		slog.Default().Warn("ignoring invalid entry in TrustedProxies", "entry", proxy)
+	}
+
+	t.proxies = &parsedProxies{source: slices.Clone(t.TrustedProxies), networks: networks}
+
+	return networks
+}
@@ -0,0 +1,134 @@
+package goMicroServiceUtils
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+/*
+=================================================================================
+Content Negotiation Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// ErrNotAcceptable is returned when none of the content types the client will accept can be produced.
+// Handlers will usually want to respond with http.StatusNotAcceptable when they see it.
+var ErrNotAcceptable = errors.New("none of the content types in the Accept header are supported (406 not acceptable)")
+
+// acceptRange is a single media range parsed from an Accept header, e.g. application/xml;q=0.9.
+type acceptRange struct {
+	mainType string
+	subType  string
+	q        float64
+}
+
+// parseAccept splits an Accept header into its media ranges. Ranges with an unparseable q value are
+// treated as q=1, as most servers do.
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(fields[0]))
+		if mediaType == "" {
+			continue
+		}
+
+		mainType, subType, found := strings.Cut(mediaType, "/")
+		if !found {
+			continue
+		}
+
+		ar := acceptRange{mainType: mainType, subType: subType, q: 1}
+		for _, param := range fields[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.ToLower(strings.TrimSpace(key)) == "q" {
+				if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					ar.q = q
+				}
+			}
+		}
+		ranges = append(ranges, ar)
+	}
+	return ranges
+}
+
+// acceptQuality returns the weight the client gives to mediaType, taken from the most specific matching
+// range in ranges, or 0 when nothing matches.
+func acceptQuality(ranges []acceptRange, mediaType string) float64 {
+	mainType, subType, _ := strings.Cut(mediaType, "/")
+
+	q, specificity := 0.0, -1
+	for _, ar := range ranges {
+		var s int
+		switch {
+		case ar.mainType == mainType && ar.subType == subType:
+			s = 2
+		case ar.mainType == mainType && ar.subType == "*":
+			s = 1
+		case ar.mainType == "*" && ar.subType == "*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = ar.q, s
+		}
+	}
+	return q
+}
+
+// negotiateContentType picks the entry of offers the client weights highest in its Accept header. Ties go
+// to the earliest offer, and a missing header selects the first offer. An empty string means nothing
+// offered is acceptable.
+func negotiateContentType(r *http.Request, offers ...string) string {
+	header := r.Header.Get("Accept")
+	if strings.TrimSpace(header) == "" {
+		return offers[0]
+	}
+
+	ranges := parseAccept(header)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := acceptQuality(ranges, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// WriteResponse inspects the request's Accept header and writes data as either JSON or XML, using
+// WriteJSON or WriteXML respectively. JSON is used when the header is missing or accepts anything.
+// If neither format is acceptable ErrNotAcceptable is returned and nothing is written.
+func (t *Tools) WriteResponse(w http.ResponseWriter, r *http.Request, status int, data interface{}, headers ...http.Header) error {
+	switch negotiateContentType(r, "application/json", "application/xml", "text/xml") {
+	case "application/json":
+		return t.WriteJSON(w, status, data, headers...)
+	case "application/xml", "text/xml":
+		return t.WriteXML(w, status, data, headers...)
+	default:
+		return ErrNotAcceptable
+	}
+}
+
+// RedirectOrJSON sends a browser to location with http.Redirect and the given redirect status, but gives an
+// API client, one whose Accept header prefers application/json over text/html, a 200 JSON response with the
+// location in the Data field instead, as a script can't read a redirect it has followed. A missing or
+// wildcard Accept header is treated as a browser.
+func (t *Tools) RedirectOrJSON(w http.ResponseWriter, r *http.Request, location string, status int) error {
+	w.Header().Add("Vary", "Accept")
+
+	if negotiateContentType(r, "text/html", "application/json") != "application/json" {
+		http.Redirect(w, r, location, status)
+		return nil
+	}
+
+	var payload JSONResponse
+	payload.Message = "redirect"
+	payload.Data = map[string]string{"location": location}
+
+	return t.WriteJSON(w, http.StatusOK, payload)
+}
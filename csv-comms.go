@@ -0,0 +1,223 @@
+package goMicroServiceUtils
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+=================================================================================
+CSV Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// csvField maps a CSV column name to the index of the struct field it is read into or written from.
+type csvField struct {
+	name  string
+	index int
+}
+
+/*
+=================================================================================
+CSV Request/Response Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// ReadCSV reads CSV with a header row from r, and unmarshals each row into out, which must be a pointer to a
+// slice of structs (or of pointers to structs). Columns are matched to fields by their `csv` struct tag, or by
+// field name when there is no tag; a tag of "-" skips the field, and unmatched columns are ignored. Strings,
+// bools, ints, uints, floats and time.Time (RFC 3339) fields are supported.
+func (t *Tools) ReadCSV(r io.Reader, out interface{}) error {
+	slicePtr := reflect.ValueOf(out)
+	if slicePtr.Kind() != reflect.Pointer || slicePtr.Elem().Kind() != reflect.Slice {
+		return errors.New("out must be a pointer to a slice of structs")
+	}
+	slice := slicePtr.Elem()
+
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Pointer
+	structType := elemType
+	if isPtr {
+		structType = elemType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return errors.New("out must be a pointer to a slice of structs")
+	}
+
+	reader := csv.NewReader(r)
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return errors.New("csv must have a header row")
+	}
+	if err != nil {
+		return fmt.Errorf("error reading csv: %s", err.Error())
+	}
+
+	// Work out which struct field, if any, each column maps to.
+	fieldByName := make(map[string]int)
+	for _, f := range csvFields(structType) {
+		fieldByName[f.name] = f.index
+	}
+	columns := make([]int, len(header))
+	for i, name := range header {
+		index, ok := fieldByName[strings.TrimSpace(name)]
+		if !ok {
+			index = -1
+		}
+		columns[i] = index
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading csv: %s", err.Error())
+		}
+
+		elem := reflect.New(structType).Elem()
+		for col, value := range record {
+			if col >= len(columns) || columns[col] < 0 {
+				continue
+			}
+
+			err = setFieldFromString(elem.Field(columns[col]), value)
+			if err != nil {
+				line, _ := reader.FieldPos(col)
+				return fmt.Errorf("csv line %d, column %q: %s", line, header[col], err.Error())
+			}
+		}
+
+		if isPtr {
+			slice.Set(reflect.Append(slice, elem.Addr()))
+		} else {
+			slice.Set(reflect.Append(slice, elem))
+		}
+	}
+
+	return nil
+}
+
+// WriteCSV streams records, a slice of structs (or of pointers to structs), to the client as a downloadable
+// CSV file called filename. The header row and columns follow the same `csv` struct tags as ReadCSV.
+func (t *Tools) WriteCSV(w http.ResponseWriter, filename string, records interface{}) error {
+	slice := reflect.ValueOf(records)
+	if slice.Kind() != reflect.Slice {
+		return errors.New("records must be a slice of structs")
+	}
+
+	structType := slice.Type().Elem()
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return errors.New("records must be a slice of structs")
+	}
+
+	fields := csvFields(structType)
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", sanitizeDisplayName(filename)))
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+
+	row := make([]string, len(fields))
+	for i, f := range fields {
+		row[i] = f.name
+	}
+	err := writer.Write(row)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < slice.Len(); i++ {
+		elem := slice.Index(i)
+		if elem.Kind() == reflect.Pointer {
+			if elem.IsNil() {
+				continue
+			}
+			elem = elem.Elem()
+		}
+
+		for j, f := range fields {
+			row[j] = formatCSVField(elem.Field(f.index))
+		}
+		err = writer.Write(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvFields lists the exported fields of structType that map to CSV columns, in declaration order.
+func csvFields(structType reflect.Type) []csvField {
+	var fields []csvField
+	for i := 0; i < structType.NumField(); i++ {
+		sf := structType.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := sf.Name
+		if tag, ok := sf.Tag.Lookup("csv"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, csvField{name: name, index: i})
+	}
+
+	return fields
+}
+
+// formatCSVField formats the struct field v as a CSV value.
+func formatCSVField(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	if tm, ok := v.Interface().(time.Time); ok {
+		if tm.IsZero() {
+			return ""
+		}
+		return tm.Format(time.RFC3339)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,65 @@
+package goMicroServiceUtils
+
+import (
+	"errors"
+	"net/http"
+)
+
+/*
+=================================================================================
+Error Status Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// errorStatus pairs a registered error with the response status code used for it.
+type errorStatus struct {
+	err    error
+	status int
+}
+
+/*
+=================================================================================
+Error Status Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// RegisterErrorStatus records the response status code ErrorJSONAuto should use for err, and for any error
+// wrapping it. Registering the same error again replaces its status code.
+func (t *Tools) RegisterErrorStatus(err error, status int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, es := range t.errorStatuses {
+		if es.err == err {
+			t.errorStatuses[i].status = status
+			return
+		}
+	}
+
+	t.errorStatuses = append(t.errorStatuses, errorStatus{err: err, status: status})
+}
+
+// ErrorJSONAuto sends a JSON error response for err, with the status code registered for it using
+// RegisterErrorStatus. Errors are matched with errors.Is, in the order they were registered, and anything
+// unregistered gets http.StatusBadRequest.
+func (t *Tools) ErrorJSONAuto(w http.ResponseWriter, err error) error {
+	return t.ErrorJSON(w, err, t.statusForError(err))
+}
+
+// statusForError returns the status code registered for err, or http.StatusBadRequest.
+func (t *Tools) statusForError(err error) int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, es := range t.errorStatuses {
+		if errors.Is(err, es.err) {
+			return es.status
+		}
+	}
+
+	return http.StatusBadRequest
+}
@@ -0,0 +1,135 @@
+package goMicroServiceUtils
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+=================================================================================
+Error Translation Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// languageRange is a single language range parsed from an Accept-Language header, e.g. fr-CH;q=0.9.
+type languageRange struct {
+	tag string
+	q   float64
+}
+
+/*
+=================================================================================
+Error Translation Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// SetErrorTranslations registers table, which maps error messages to their translation in lang (e.g. "fr"
+// or "pt-BR"), for use by ErrorJSONLocalized. Registering the same language again replaces its table. The
+// first language registered is the default, used when the client's Accept-Language matches nothing.
+func (t *Tools) SetErrorTranslations(lang string, table map[string]string) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+
+	// Copy the table, so later changes to the caller's map can't race with requests.
+	translated := make(map[string]string, len(table))
+	for key, value := range table {
+		translated[key] = value
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.translations == nil {
+		t.translations = make(map[string]map[string]string)
+	}
+	if _, ok := t.translations[lang]; !ok {
+		t.languages = append(t.languages, lang)
+	}
+	t.translations[lang] = translated
+}
+
+// ErrorJSONLocalized behaves like ErrorJSON, but translates the error message into the language the client
+// asks for in its Accept-Language header, using the tables registered with SetErrorTranslations. The error's
+// message is the translation key; messages with no translation are sent as they are, without a
+// Content-Language header.
+func (t *Tools) ErrorJSONLocalized(w http.ResponseWriter, r *http.Request, err error, status ...int) error {
+	message := err.Error()
+
+	t.mu.RLock()
+	lang := t.matchLanguage(r.Header.Get("Accept-Language"))
+	translated, ok := t.translations[lang][message]
+	t.mu.RUnlock()
+
+	// Only label the response with a language when the message was actually translated into it.
+	if ok {
+		w.Header().Set("Content-Language", lang)
+		err = errors.New(translated)
+	}
+
+	return t.ErrorJSON(w, err, status...)
+}
+
+// matchLanguage returns the registered language that best matches an Accept-Language header, falling back to
+// the first registered language. A range like "en-GB" also matches a registered "en", and vice versa. It
+// returns "" if no languages are registered. The caller must hold t.mu.
+func (t *Tools) matchLanguage(header string) string {
+	if len(t.languages) == 0 {
+		return ""
+	}
+
+	for _, lr := range parseAcceptLanguage(header) {
+		if lr.q <= 0 {
+			continue
+		}
+		if lr.tag == "*" {
+			break
+		}
+		if _, ok := t.translations[lr.tag]; ok {
+			return lr.tag
+		}
+
+		base, _, _ := strings.Cut(lr.tag, "-")
+		for _, lang := range t.languages {
+			if registeredBase, _, _ := strings.Cut(lang, "-"); registeredBase == base {
+				return lang
+			}
+		}
+	}
+
+	return t.languages[0]
+}
+
+// parseAcceptLanguage splits an Accept-Language header into its language ranges, most preferred first.
+// Ranges with an unparseable q value are treated as q=1, as with parseAccept.
+func parseAcceptLanguage(header string) []languageRange {
+	var ranges []languageRange
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+
+		lr := languageRange{tag: tag, q: 1}
+		for _, param := range fields[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.ToLower(strings.TrimSpace(key)) == "q" {
+				if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					lr.q = q
+				}
+			}
+		}
+		ranges = append(ranges, lr)
+	}
+
+	// Keep the header's order between ranges of equal weight.
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+
+	return ranges
+}
@@ -0,0 +1,96 @@
+package goMicroServiceUtils
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+/*
+=================================================================================
+Field Parsing Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// setFieldFromString parses value into the struct field v, according to the field's type. Pointer fields are
+// allocated and set to the parsed value, so callers can tell a missing value from a zero one.
+func setFieldFromString(v reflect.Value, value string) error {
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+		err := setFieldFromString(ptr.Elem(), value)
+		if err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	}
+
+	if v.Type() == reflect.TypeOf(time.Time{}) {
+		if value == "" {
+			return nil
+		}
+		// Accept plain dates as well as full timestamps.
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			parsed, err = time.Parse(time.DateOnly, value)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot parse %q as an RFC 3339 time", value)
+		}
+		v.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+
+	case reflect.Bool:
+		if value == "" {
+			return nil
+		}
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("cannot parse %q as a bool", value)
+		}
+		v.SetBool(parsed)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value == "" {
+			return nil
+		}
+		parsed, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("cannot parse %q as an integer", value)
+		}
+		v.SetInt(parsed)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value == "" {
+			return nil
+		}
+		parsed, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("cannot parse %q as an unsigned integer", value)
+		}
+		v.SetUint(parsed)
+
+	case reflect.Float32, reflect.Float64:
+		if value == "" {
+			return nil
+		}
+		parsed, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("cannot parse %q as a number", value)
+		}
+		v.SetFloat(parsed)
+
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package goMicroServiceUtils
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+/*
+=================================================================================
+File Download Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// DownloadStaticFile downloads a file, and tries to force the browser to avoid displaying it in the browser
+// window by setting Content-Disposition. It also allows specification of the display name. Files that do not
+// exist get a plain 404, so the server's file system layout is never revealed to the client. The file's
+// modification time and an ETag derived from it and the file's size are sent, so repeat downloads of an
+// unchanged file with If-Modified-Since or If-None-Match get a 304 Not Modified and no body.
+func (t *Tools) DownloadStaticFile(w http.ResponseWriter, r *http.Request, pathName, displayName string) {
+	info, err := os.Stat(pathName)
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	// http.ServeFile checks If-None-Match against this ETag, and If-Modified-Since against the modtime.
+	w.Header().Set("ETag", fileETag(info))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", sanitizeDisplayName(displayName)))
+
+	http.ServeFile(w, r, pathName)
+}
+
+// ServeInline serves the file at path for display in the browser, e.g. an image or PDF, rather than as a
+// download. The content type comes from the file's extension, falling back to sniffing its contents, and
+// range requests and conditional requests are supported as with DownloadStaticFile. Paths containing ".."
+// segments are rejected with a 400, so a path built from user input can't reach outside its directory.
+func (t *Tools) ServeInline(w http.ResponseWriter, r *http.Request, path string) {
+	for _, segment := range strings.FieldsFunc(path, func(c rune) bool { return c == '/' || c == '\\' }) {
+		if segment == ".." {
+			http.Error(w, "invalid file path", http.StatusBadRequest)
+			return
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Prefer the extension, and only sniff the contents when it doesn't tell us anything.
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		buf := make([]byte, 512)
+		n, _ := f.Read(buf)
+		contentType = http.DetectContentType(buf[:n])
+		_, err = f.Seek(0, io.SeekStart)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("ETag", fileETag(info))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", sanitizeDisplayName(filepath.Base(path))))
+
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+// ProxyDownload fetches remoteURL, e.g. a file in internal storage, and streams it to the client without
+// buffering it, passing through the Content-Type, Content-Length and Content-Disposition headers. Range and
+// If-Range request headers are forwarded, and 206 Partial Content responses passed back with their
+// Content-Range, so clients can resume interrupted downloads. If the remote server responds with anything
+// other than 200, 206 or 416, nothing is written and an error is returned for the handler to deal with.
+func (t *Tools) ProxyDownload(w http.ResponseWriter, r *http.Request, remoteURL string) error {
+	request, err := http.NewRequestWithContext(r.Context(), http.MethodGet, remoteURL, nil)
+	if err != nil {
+		return err
+	}
+	for _, header := range []string{"Range", "If-Range"} {
+		if value := r.Header.Get(header); value != "" {
+			request.Header.Set(header, value)
+		}
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusPartialContent, http.StatusRequestedRangeNotSatisfiable:
+	default:
+		return fmt.Errorf("remote server returned status %d for %s", response.StatusCode, remoteURL)
+	}
+
+	for _, header := range []string{"Content-Type", "Content-Length", "Content-Disposition", "Content-Range", "Accept-Ranges", "ETag", "Last-Modified"} {
+		if value := response.Header.Get(header); value != "" {
+			w.Header().Set(header, value)
+		}
+	}
+	w.WriteHeader(response.StatusCode)
+
+	_, err = io.Copy(w, response.Body)
+
+	return err
+}
+
+// fileETag returns a weak ETag for a file, derived from its size and modification time, so it changes
+// whenever the file does without the file having to be read.
+func fileETag(info os.FileInfo) string {
+	return fmt.Sprintf("W/\"%x-%x\"", info.Size(), info.ModTime().UnixNano())
+}
+
+// sanitizeDisplayName strips path separators, quotes and control characters from a file name destined for
+// a Content-Disposition header, so it can't escape the quoted value or inject further headers.
+func sanitizeDisplayName(displayName string) string {
+	cleaned := strings.Map(func(c rune) rune {
+		if c == '/' || c == '\\' || c == '"' || unicode.IsControl(c) {
+			return -1
+		}
+		return c
+	}, displayName)
+
+	if strings.TrimSpace(cleaned) == "" {
+		return "download"
+	}
+
+	return cleaned
+}
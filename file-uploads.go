@@ -0,0 +1,372 @@
+package goMicroServiceUtils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // register the GIF decoder for image.DecodeConfig
+	_ "image/jpeg" // register the JPEG decoder for image.DecodeConfig
+	_ "image/png"  // register the PNG decoder for image.DecodeConfig
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+=================================================================================
+File Upload Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// UploadedFile is a struct used to save information about an uploaded file.
+type UploadedFile struct {
+	NewFileName      string // name of the file as saved in the upload directory
+	OriginalFileName string // name of the file as submitted by the client
+	FileSize         int64  // size of the saved file in bytes
+	SHA256           string // hex-encoded SHA-256 of the saved file, when Checksum is set on Tools
+}
+
+// UploadErrorReason describes why an uploaded file was rejected.
+type UploadErrorReason string
+
+const (
+	UploadErrorSize  UploadErrorReason = "size"  // the file was larger than MaxFileSize
+	UploadErrorType  UploadErrorReason = "type"  // the file's detected type is not in AllowedFileTypes
+	UploadErrorImage UploadErrorReason = "image" // the image is larger than MaxImageWidth or MaxImageHeight
+	UploadErrorRead  UploadErrorReason = "read"  // the uploaded file could not be read
+	UploadErrorWrite UploadErrorReason = "write" // the file could not be written to the upload directory
+)
+
+// UploadError is returned when a single uploaded file is rejected, so handlers can tell the user which file
+// was the problem. When several files fail, UploadFiles joins their UploadErrors with errors.Join; use
+// errors.As to get at the first one.
+type UploadError struct {
+	FileName string            // original name of the file as submitted by the client
+	FileType string            // MIME type detected from the file content, if known
+	Reason   UploadErrorReason // why the file was rejected
+	MaxSize  int64             // the size limit that was exceeded, for UploadErrorSize
+	Err      error             // the underlying error, for UploadErrorRead, UploadErrorWrite and UploadErrorImage
+}
+
+// Error implements the error interface.
+func (e *UploadError) Error() string {
+	switch e.Reason {
+	case UploadErrorSize:
+		return fmt.Sprintf("the uploaded file %s is too big, it must be no larger than %d bytes", e.FileName, e.MaxSize)
+	case UploadErrorType:
+		return fmt.Sprintf("%s is not an allowed type (%s)", e.FileName, e.FileType)
+	case UploadErrorImage:
+		return fmt.Sprintf("the uploaded image %s is too large: %v", e.FileName, e.Err)
+	default:
+		return fmt.Sprintf("the uploaded file %s could not be saved: %v", e.FileName, e.Err)
+	}
+}
+
+// Unwrap returns the underlying error, if any.
+func (e *UploadError) Unwrap() error {
+	return e.Err
+}
+
+/*
+=================================================================================
+File Upload Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// UploadFiles uploads one or more files from a multipart form request to the specified directory, and returns
+// information about the newly uploaded files. The optional last parameter, rename, controls whether files are
+// given a random name (the default) or keep their original name. Files are returned ordered by form field name,
+// then in the order they were sent within each field. If any file in the request is too large or of a disallowed
+// type, the whole batch is rejected and nothing, not even uploadDir, is written to disk.
+func (t *Tools) UploadFiles(r *http.Request, uploadDir string, rename ...bool) ([]*UploadedFile, error) {
+	renameFile := true
+	if len(rename) > 0 {
+		renameFile = rename[0]
+	}
+
+	// Files up to 32MB are held in memory while parsing, anything larger is spooled to temporary files.
+	err := r.ParseMultipartForm(32 << 20)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing multipart form: %s", err.Error())
+	}
+
+	// The parsed form is a map, so go through its fields in name order to keep the result stable. Files sent
+	// under the same field keep the order they were sent in.
+	fields := make([]string, 0, len(r.MultipartForm.File))
+	for field := range r.MultipartForm.File {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var headers []*multipart.FileHeader
+	for _, field := range fields {
+		headers = append(headers, r.MultipartForm.File[field]...)
+	}
+
+	// Fail fast on requests carrying too many files, before any of them are looked at.
+	if t.MaxUploadCount > 0 && len(headers) > t.MaxUploadCount {
+		return nil, fmt.Errorf("too many files uploaded, at most %d are allowed", t.MaxUploadCount)
+	}
+
+	// Validate every file before writing any of them, so a bad file rejects the whole batch cleanly. All the
+	// failures are collected, so the client can be told about every bad file at once.
+	var validationErrors []error
+	for _, hdr := range headers {
+		err := t.checkUploadedFile(hdr)
+		if err != nil {
+			validationErrors = append(validationErrors, err)
+		}
+	}
+	if len(validationErrors) == 1 {
+		return nil, validationErrors[0]
+	}
+	if len(validationErrors) > 1 {
+		return nil, errors.Join(validationErrors...)
+	}
+
+	// Only create the destination once every file has passed, so rejected requests leave nothing on disk.
+	err = t.CreateDirIfNotExist(uploadDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var uploadedFiles []*UploadedFile
+	for _, hdr := range headers {
+		uploadedFile, err := t.saveUploadedFile(hdr, uploadDir, renameFile)
+		if err != nil {
+			// Remove anything already written so a failed batch doesn't leave partial uploads behind.
+			for _, f := range uploadedFiles {
+				_ = os.Remove(filepath.Join(uploadDir, f.NewFileName))
+			}
+			return nil, err
+		}
+		uploadedFiles = append(uploadedFiles, uploadedFile)
+	}
+
+	return uploadedFiles, nil
+}
+
+// SaveFile validates the file read from src against MaxFileSize and AllowedFileTypes, and writes it to uploadDir,
+// for files that arrive from somewhere other than an HTTP upload, such as a message queue. As with UploadFiles,
+// the type is sniffed from the content, and the optional last parameter, rename, controls whether the file is
+// given a random name (the default) or keeps filename. Rejected files are returned as an *UploadError.
+func (t *Tools) SaveFile(src io.Reader, filename, uploadDir string, rename ...bool) (*UploadedFile, error) {
+	renameFile := true
+	if len(rename) > 0 {
+		renameFile = rename[0]
+	}
+
+	src, _, err := t.checkFileType(src, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure the destination exists before we try to write anything into it.
+	err = t.CreateDirIfNotExist(uploadDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var uploadedFile UploadedFile
+	uploadedFile.OriginalFileName = filename
+	if renameFile {
+		uploadedFile.NewFileName = fmt.Sprintf("%s%s", t.RandomString(25), filepath.Ext(filename))
+	} else {
+		uploadedFile.NewFileName = filepath.Base(filename)
+	}
+
+	outfile, err := os.Create(filepath.Join(uploadDir, uploadedFile.NewFileName))
+	if err != nil {
+		return nil, &UploadError{FileName: filename, Reason: UploadErrorWrite, Err: err}
+	}
+	defer outfile.Close()
+
+	// Copy at most one byte more than the limit, which is enough to tell that the file is too big without
+	// reading the rest of it.
+	maxFileSize := t.maxFileSize()
+
+	// When asked for a checksum, hash the file as it is written, so large files aren't read twice.
+	var dst io.Writer = outfile
+	hash := sha256.New()
+	if t.Checksum {
+		dst = io.MultiWriter(outfile, hash)
+	}
+
+	fileSize, err := io.Copy(dst, io.LimitReader(src, maxFileSize+1))
+	if err != nil {
+		_ = os.Remove(outfile.Name())
+		return nil, &UploadError{FileName: filename, Reason: UploadErrorWrite, Err: err}
+	}
+	if fileSize > maxFileSize {
+		_ = os.Remove(outfile.Name())
+		return nil, &UploadError{FileName: filename, Reason: UploadErrorSize, MaxSize: maxFileSize}
+	}
+	uploadedFile.FileSize = fileSize
+	if t.Checksum {
+		uploadedFile.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	return &uploadedFile, nil
+}
+
+// maxFileSize returns MaxFileSize, or a default of one gigabyte if it isn't set.
+func (t *Tools) maxFileSize() int64 {
+	// Set a sensible default for the maximum file size.
+	maxFileSize := 1024 * 1024 * 1024 // one gigabyte
+
+	// If MaxFileSize is set, use that value instead of default.
+	if t.MaxFileSize != 0 {
+		maxFileSize = t.MaxFileSize
+	}
+
+	return int64(maxFileSize)
+}
+
+// isAllowedFileType reports whether fileType is in AllowedFileTypes. An empty list allows every type.
+func (t *Tools) isAllowedFileType(fileType string) bool {
+	if len(t.AllowedFileTypes) == 0 {
+		return true
+	}
+
+	// Sniffed types may carry parameters, e.g. text/plain; charset=utf-8, so also compare the bare media type.
+	mediaType := strings.TrimSpace(strings.Split(fileType, ";")[0])
+	for _, allowedType := range t.AllowedFileTypes {
+		if strings.EqualFold(fileType, allowedType) || strings.EqualFold(mediaType, allowedType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkUploadedFile checks a multipart file against MaxFileSize and AllowedFileTypes without saving it.
+func (t *Tools) checkUploadedFile(hdr *multipart.FileHeader) error {
+	if hdr.Size > t.maxFileSize() {
+		return &UploadError{FileName: hdr.Filename, Reason: UploadErrorSize, MaxSize: t.maxFileSize()}
+	}
+
+	infile, err := hdr.Open()
+	if err != nil {
+		return &UploadError{FileName: hdr.Filename, Reason: UploadErrorRead, Err: err}
+	}
+	defer infile.Close()
+
+	_, _, err = t.checkFileType(infile, hdr.Filename)
+	return err
+}
+
+// checkFileType sniffs the MIME type of src from its first 512 bytes with http.DetectContentType, rather than
+// trusting any type the client supplied, and checks it against AllowedFileTypes. The bytes used for sniffing
+// are consumed from src, so the returned reader must be used to read the whole file.
+func (t *Tools) checkFileType(src io.Reader, filename string) (io.Reader, string, error) {
+	buff := make([]byte, 512)
+	n, err := io.ReadFull(src, buff)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, "", &UploadError{FileName: filename, Reason: UploadErrorRead, Err: err}
+	}
+
+	fileType := http.DetectContentType(buff[:n])
+	if !t.isAllowedFileType(fileType) {
+		return nil, fileType, &UploadError{FileName: filename, FileType: fileType, Reason: UploadErrorType}
+	}
+
+	src = io.MultiReader(bytes.NewReader(buff[:n]), src)
+
+	if strings.HasPrefix(fileType, "image/") && (t.MaxImageWidth > 0 || t.MaxImageHeight > 0) {
+		src, err = t.checkImageDimensions(src, filename, fileType)
+		if err != nil {
+			return nil, fileType, err
+		}
+	}
+
+	return src, fileType, nil
+}
+
+// checkImageDimensions reads just the header of the image in src, using image.DecodeConfig so the pixels are
+// never decoded, and checks its size against MaxImageWidth and MaxImageHeight. Formats with no registered
+// decoder are let through. The header bytes are consumed from src, so the returned reader must be used to
+// read the whole file.
+func (t *Tools) checkImageDimensions(src io.Reader, filename, fileType string) (io.Reader, error) {
+	var header bytes.Buffer
+	config, _, err := image.DecodeConfig(io.TeeReader(src, &header))
+	src = io.MultiReader(&header, src)
+	if errors.Is(err, image.ErrFormat) {
+		return src, nil
+	}
+	if err != nil {
+		return nil, &UploadError{FileName: filename, FileType: fileType, Reason: UploadErrorRead, Err: err}
+	}
+
+	if (t.MaxImageWidth > 0 && config.Width > t.MaxImageWidth) || (t.MaxImageHeight > 0 && config.Height > t.MaxImageHeight) {
+		err = fmt.Errorf("it is %dx%d pixels, but the maximum allowed is %sx%s", config.Width, config.Height,
+			formatImageLimit(t.MaxImageWidth), formatImageLimit(t.MaxImageHeight))
+		return nil, &UploadError{FileName: filename, FileType: fileType, Reason: UploadErrorImage, Err: err}
+	}
+
+	return src, nil
+}
+
+// formatImageLimit formats an image dimension limit for an error message, where zero means unlimited.
+func formatImageLimit(limit int) string {
+	if limit <= 0 {
+		return "any"
+	}
+	return strconv.Itoa(limit)
+}
+
+// saveUploadedFile saves a single multipart file into uploadDir using SaveFile.
+func (t *Tools) saveUploadedFile(hdr *multipart.FileHeader, uploadDir string, renameFile bool) (*UploadedFile, error) {
+	infile, err := hdr.Open()
+	if err != nil {
+		return nil, &UploadError{FileName: hdr.Filename, Reason: UploadErrorRead, Err: err}
+	}
+	defer infile.Close()
+
+	return t.SaveFile(infile, hdr.Filename, uploadDir, renameFile)
+}
+
+// UploadOneFile is a convenience wrapper around UploadFiles for handlers that only accept a single file. It
+// returns the first uploaded file in UploadFiles' order, i.e. the first file sent under the alphabetically first
+// field name, or an error if the request contained no files.
+func (t *Tools) UploadOneFile(r *http.Request, uploadDir string, rename ...bool) (*UploadedFile, error) {
+	files, err := t.UploadFiles(r, uploadDir, rename...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, errors.New("no file was uploaded")
+	}
+
+	return files[0], nil
+}
+
+// ReadMultipartForm reads a multipart form containing both files and ordinary fields, such as an image and
+// its caption, parsing the body only once. Files are validated and saved to uploadDir exactly as UploadFiles
+// does, and the non-file fields are returned alongside them.
+func (t *Tools) ReadMultipartForm(r *http.Request, uploadDir string, rename ...bool) (map[string][]string, []*UploadedFile, error) {
+	// UploadFiles parses the form; the parsed fields are then left on the request for us to pick up.
+	files, err := t.UploadFiles(r, uploadDir, rename...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fields := make(map[string][]string, len(r.MultipartForm.Value))
+	for key, values := range r.MultipartForm.Value {
+		fields[key] = values
+	}
+
+	return fields, files, nil
+}
@@ -0,0 +1,44 @@
+package goMicroServiceUtils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+)
+
+/*
+=================================================================================
+File System Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// CreateDirIfNotExist creates a directory, and all necessary parents, if it does not already exist.
+func (t *Tools) CreateDirIfNotExist(path string) error {
+	const mode = 0755
+
+	// os.MkdirAll returns nil if the directory already exists.
+	return os.MkdirAll(path, mode)
+}
+
+// VerifyFileChecksum reports whether the SHA-256 of the file at path matches expected, a hex-encoded checksum
+// such as UploadedFile.SHA256, so stored files can be checked for corruption or tampering. The file is streamed
+// rather than read into memory.
+func (t *Tools) VerifyFileChecksum(path, expected string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, f)
+	if err != nil {
+		return false, err
+	}
+
+	return strings.EqualFold(hex.EncodeToString(hash.Sum(nil)), strings.TrimSpace(expected)), nil
+}
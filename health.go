@@ -0,0 +1,118 @@
+package goMicroServiceUtils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+/*
+=================================================================================
+Health Check Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// defaultHealthCheckTimeout is how long a HealthCheck may run when its Timeout isn't set.
+const defaultHealthCheckTimeout = 5 * time.Second
+
+// HealthCheck is a named check of one of a service's dependencies, e.g. its database. Func should return
+// nil when the dependency is healthy, and should give up when ctx is done.
+type HealthCheck struct {
+	Name    string
+	Func    func(ctx context.Context) error
+	Timeout time.Duration // how long the check may run before it is reported as failed (zero means 5 seconds)
+}
+
+// HealthStatus is the result of a single HealthCheck, as reported by HealthHandler.
+type HealthStatus struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"` // only sent when Development is set
+}
+
+/*
+=================================================================================
+Health Check Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// HealthHandler returns a handler, suitable for /healthz or /readyz, that runs every check and responds with
+// the status of each in the Data field of a JSONResponse. The response is a 200 when every check passes, and
+// a 503 when any fails, so the failing dependency can be seen at a glance. Checks run concurrently, each with
+// a context that expires after its Timeout, and one that hasn't returned by then is reported as failed, so a
+// hung dependency can't hang the probe. Only whether each check passed is sent, since health endpoints are
+// usually unauthenticated; the error text is included too when Development is set.
+func (t *Tools) HealthHandler(checks ...HealthCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		errs := make([]chan error, len(checks))
+		for i, check := range checks {
+			errs[i] = make(chan error, 1)
+			go func(check HealthCheck, result chan<- error) {
+				result <- runHealthCheck(r.Context(), check)
+			}(check, errs[i])
+		}
+
+		statuses := make([]HealthStatus, 0, len(checks))
+		healthy := true
+
+		for i, check := range checks {
+			status := HealthStatus{Name: check.Name, Healthy: true}
+
+			err := <-errs[i]
+			if err != nil {
+				status.Healthy = false
+				if t.Development {
+					status.Error = err.Error()
+				}
+				healthy = false
+			}
+
+			statuses = append(statuses, status)
+		}
+
+		var payload JSONResponse
+		payload.Data = statuses
+
+		if !healthy {
+			payload.Error = true
+			payload.Message = "one or more health checks failed"
+			_ = t.WriteJSON(w, http.StatusServiceUnavailable, payload)
+			return
+		}
+
+		payload.Message = "ok"
+		_ = t.WriteJSON(w, http.StatusOK, payload)
+	}
+}
+
+// runHealthCheck runs check with its timeout, returning its error, or an error once the timeout passes if
+// the check hasn't returned by then. A check that ignores its context is left to finish in the background.
+func runHealthCheck(ctx context.Context, check HealthCheck) error {
+	if check.Func == nil {
+		return errors.New("no check function")
+	}
+
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Func(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return errors.New("health check timed out")
+	}
+}
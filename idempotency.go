@@ -0,0 +1,230 @@
+package goMicroServiceUtils
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+/*
+=================================================================================
+Idempotency Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// IdempotencyKeyHeader is the request header clients use to send an idempotency key.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength is the longest idempotency key accepted, so keys can't be used to bloat the store.
+const maxIdempotencyKeyLength = 255
+
+// IdempotentResponse is a response recorded by the Idempotency middleware, to be replayed for retries.
+type IdempotentResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// IdempotencyStore stores recorded responses by key for the Idempotency middleware. Implementations must be
+// safe for concurrent use; back it with memory (see Tools.NewMemoryIdempotencyStore), Redis or similar.
+type IdempotencyStore interface {
+	// Get returns the response recorded for key, and false if there isn't one or it has expired.
+	Get(key string) (*IdempotentResponse, bool, error)
+
+	// Set records resp for key, to be kept for at least ttl.
+	Set(key string, resp *IdempotentResponse, ttl time.Duration) error
+}
+
+// memoryIdempotencyEntry is a response held by memoryIdempotencyStore, and when it expires.
+type memoryIdempotencyEntry struct {
+	resp      *IdempotentResponse
+	expiresAt time.Time
+}
+
+// memoryIdempotencyStore is an in-memory IdempotencyStore, suitable for a single instance of a service.
+type memoryIdempotencyStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryIdempotencyEntry
+	lastSweep time.Time
+}
+
+// idempotencyLock is a per-key lock, counting the requests holding or waiting on it so it can be removed.
+type idempotencyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// idempotencyRecorder passes a response through to the client while keeping a copy of it for the store.
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+/*
+=================================================================================
+Idempotency Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// Idempotency returns middleware that makes retried requests safe. When a request carries an Idempotency-Key
+// header, the handler's response is recorded in store for IdempotencyTTL (24 hours by default), keyed by the
+// method, path and key, and any later request with the same key gets the recorded response replayed, with an
+// Idempotent-Replayed header, instead of running the handler again. Concurrent requests with the same key
+// are handled one at a time by this instance, so within one process the handler never runs twice for a key.
+// That lock isn't shared, so with several instances behind a shared store, two concurrent requests with the
+// same key that reach different instances can both run the handler: execution is then at least once, not
+// exactly once. 5xx responses aren't recorded, so a retry after a server error runs the handler again.
+// Requests without the header pass straight through.
+func (t *Tools) Idempotency(store IdempotencyStore) func(http.Handler) http.Handler {
+	var mu sync.Mutex
+	locks := make(map[string]*idempotencyLock)
+
+	// lock serializes requests for key, returning the function that releases it.
+	lock := func(key string) func() {
+		mu.Lock()
+		l, ok := locks[key]
+		if !ok {
+			l = &idempotencyLock{}
+			locks[key] = l
+		}
+		l.refs++
+		mu.Unlock()
+
+		l.mu.Lock()
+
+		return func() {
+			l.mu.Unlock()
+
+			mu.Lock()
+			l.refs--
+			if l.refs == 0 {
+				delete(locks, key)
+			}
+			mu.Unlock()
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				_ = t.ErrorJSON(w, errors.New("the Idempotency-Key header is too long"))
+				return
+			}
+
+			storeKey := r.Method + " " + r.URL.Path + " " + key
+			unlock := lock(storeKey)
+			defer unlock()
+
+			resp, found, err := store.Get(storeKey)
+			if err != nil {
+				_ = t.ErrorJSON(w, errors.New("the server encountered a problem and could not process your request"), http.StatusInternalServerError)
+				return
+			}
+			if found {
+				for k, v := range resp.Header {
+					w.Header()[k] = v
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(resp.StatusCode)
+				_, _ = w.Write(resp.Body)
+				return
+			}
+
+			rec := &idempotencyRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+				rec.header = w.Header().Clone()
+			}
+			if rec.status >= http.StatusInternalServerError {
+				return
+			}
+
+			ttl := t.IdempotencyTTL
+			if ttl == 0 {
+				ttl = 24 * time.Hour
+			}
+			_ = store.Set(storeKey, &IdempotentResponse{
+				StatusCode: rec.status,
+				Header:     rec.header,
+				Body:       rec.body.Bytes(),
+			}, ttl)
+		})
+	}
+}
+
+// NewMemoryIdempotencyStore returns an IdempotencyStore that keeps responses in memory. Expired responses
+// are removed as they are looked up, and are otherwise cleared out at most once a minute, as new responses
+// are stored.
+func (t *Tools) NewMemoryIdempotencyStore() IdempotencyStore {
+	return &memoryIdempotencyStore{entries: make(map[string]memoryIdempotencyEntry)}
+}
+
+// Get returns the unexpired response recorded for key.
+func (s *memoryIdempotencyStore) Get(key string) (*IdempotentResponse, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(s.entries, key)
+		return nil, false, nil
+	}
+
+	return entry.resp, true, nil
+}
+
+// Set records resp for key until ttl has passed, first clearing out any expired responses if it hasn't done
+// so in the last minute.
+func (s *memoryIdempotencyStore) Set(key string, resp *IdempotentResponse, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, entry := range s.entries {
+			if now.After(entry.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	s.entries[key] = memoryIdempotencyEntry{resp: resp, expiresAt: now.Add(ttl)}
+
+	return nil
+}
+
+// WriteHeader records the status code and headers before passing them on.
+func (rec *idempotencyRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+		rec.header = rec.ResponseWriter.Header().Clone()
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Write keeps a copy of b before passing it on.
+func (rec *idempotencyRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.WriteHeader(http.StatusOK)
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
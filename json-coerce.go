@@ -0,0 +1,255 @@
+package goMicroServiceUtils
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+=================================================================================
+JSON Type Coercion Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// RegisterTimeFormat adds layout, in the form used by time.Parse (e.g. time.RFC1123), to the formats ReadJSON
+// accepts for time.Time fields, for clients that don't send RFC 3339. RFC 3339 is always accepted; other
+// layouts are tried in the order they were registered. A time that matches none of them is rejected with
+// an error naming the field.
+func (t *Tools) RegisterTimeFormat(layout string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, existing := range t.timeFormats {
+		if existing == layout {
+			return
+		}
+	}
+	t.timeFormats = append(t.timeFormats, layout)
+}
+
+// registeredTimeFormats returns the layouts registered with RegisterTimeFormat.
+func (t *Tools) registeredTimeFormats() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.timeFormats
+}
+
+// decodeLenient decodes body into data a second time, for ReadJSON after a strict decode fails. When
+// CoerceJSONTypes is set, strings that hold a number or bool are converted into that number or bool wherever
+// data's type expects one; strings in time.Time fields are parsed with timeFormats and converted to RFC 3339.
+// Anything else, including strings that don't parse cleanly, is left for the strict decode to reject. It
+// returns a decoder positioned after the first value in body, so the caller can check for trailing data.
+func (t *Tools) decodeLenient(body []byte, data interface{}, timeFormats []string) (*json.Decoder, error) {
+	bodyDec := json.NewDecoder(bytes.NewReader(body))
+	bodyDec.UseNumber()
+
+	var doc interface{}
+	err := bodyDec.Decode(&doc)
+	if err != nil {
+		return bodyDec, err
+	}
+
+	l := lenientDecoding{coerce: t.CoerceJSONTypes, timeFormats: timeFormats}
+	converted, err := l.convert(doc, reflect.TypeOf(data), "")
+	if err != nil {
+		return bodyDec, err
+	}
+
+	lenient, err := json.Marshal(converted)
+	if err != nil {
+		return bodyDec, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(lenient))
+	if !t.AllowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if t.UseJSONNumber {
+		dec.UseNumber()
+	}
+
+	return bodyDec, dec.Decode(data)
+}
+
+// lenientDecoding holds the conversions decodeLenient applies.
+type lenientDecoding struct {
+	coerce      bool     // unwrap string-wrapped numbers and bools
+	timeFormats []string // extra layouts for time.Time fields
+}
+
+// timeType is the type of time.Time, whose fields get the registered time formats.
+var timeType = reflect.TypeOf(time.Time{})
+
+// convert walks the decoded JSON value v alongside the Go type it will be decoded into, converting values
+// where typ expects something else. field is the dotted path to v, used in error messages.
+func (l lenientDecoding) convert(v interface{}, typ reflect.Type, field string) (interface{}, error) {
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == timeType {
+		return l.convertTime(v, field)
+	}
+	if typ == nil || hasCustomJSONDecoding(typ) {
+		return v, nil
+	}
+
+	switch value := v.(type) {
+	case string:
+		if l.coerce {
+			return coerceJSONString(value, typ), nil
+		}
+
+	case []interface{}:
+		if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+			for i := range value {
+				converted, err := l.convert(value[i], typ.Elem(), field)
+				if err != nil {
+					return nil, err
+				}
+				value[i] = converted
+			}
+		}
+
+	case map[string]interface{}:
+		switch typ.Kind() {
+		case reflect.Map:
+			for key := range value {
+				converted, err := l.convert(value[key], typ.Elem(), joinJSONField(field, key))
+				if err != nil {
+					return nil, err
+				}
+				value[key] = converted
+			}
+		case reflect.Struct:
+			fields := jsonFieldTypes(typ)
+			for key := range value {
+				if fieldType, ok := fields[strings.ToLower(key)]; ok {
+					converted, err := l.convert(value[key], fieldType, joinJSONField(field, key))
+					if err != nil {
+						return nil, err
+					}
+					value[key] = converted
+				}
+			}
+		}
+	}
+
+	return v, nil
+}
+
+// convertTime parses a time sent as v, trying RFC 3339 and then each registered layout, and returns it in
+// RFC 3339 form. Values that aren't strings are returned unchanged for the strict decode to deal with.
+func (l lenientDecoding) convertTime(v interface{}, field string) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok || len(l.timeFormats) == 0 {
+		return v, nil
+	}
+
+	if _, err := time.Parse(time.RFC3339, s); err == nil {
+		return v, nil
+	}
+	for _, layout := range l.timeFormats {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			return parsed.Format(time.RFC3339Nano), nil
+		}
+	}
+
+	return nil, fmt.Errorf("body contains invalid time for field %q", field)
+}
+
+// coerceJSONString returns the number or bool held in s when typ expects one and s holds nothing else, or s
+// unchanged.
+func coerceJSONString(s string, typ reflect.Type) interface{} {
+	trimmed := strings.TrimSpace(s)
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if _, err := strconv.ParseInt(trimmed, 10, typ.Bits()); err == nil {
+			return json.Number(trimmed)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, err := strconv.ParseUint(trimmed, 10, typ.Bits()); err == nil {
+			return json.Number(trimmed)
+		}
+	case reflect.Float32, reflect.Float64:
+		// ParseFloat also accepts NaN, Inf and hex floats, none of which are valid JSON numbers.
+		f, err := strconv.ParseFloat(trimmed, typ.Bits())
+		if err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) && json.Valid([]byte(trimmed)) {
+			return json.Number(trimmed)
+		}
+	case reflect.Bool:
+		switch trimmed {
+		case "true":
+			return true
+		case "false":
+			return false
+		}
+	}
+
+	return s
+}
+
+// joinJSONField appends key to the dotted field path parent, matching the Field of json.UnmarshalTypeError.
+func joinJSONField(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+// hasCustomJSONDecoding reports whether typ, or a pointer to it, decodes itself, e.g. time.Time, in which case
+// its JSON is left alone.
+func hasCustomJSONDecoding(typ reflect.Type) bool {
+	ptr := reflect.PointerTo(typ)
+	return ptr.Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) ||
+		ptr.Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem())
+}
+
+// jsonFieldTypes maps the lowercased JSON names of structType's fields, including those promoted from
+// embedded structs, to their types. Names are lowercased because encoding/json matches keys to fields
+// case-insensitively.
+func jsonFieldTypes(structType reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < structType.NumField(); i++ {
+		sf := structType.Field(i)
+
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		fieldType := sf.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if sf.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			for embeddedName, embeddedType := range jsonFieldTypes(fieldType) {
+				if _, exists := fields[embeddedName]; !exists {
+					fields[embeddedName] = embeddedType
+				}
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+		fields[strings.ToLower(name)] = sf.Type
+	}
+
+	return fields
+}
@@ -1,12 +1,22 @@
 package goMicroServiceUtils
 
 import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 /*
@@ -20,18 +30,59 @@ JSON Structures
 // Tools is the type for this package. Create a variable of this type, and you have access
 // to all the exported methods with the receiver type *Tools.
 type Tools struct {
-	MaxJSONSize        int      // maximum size of JSON file we'll process
-	MaxXMLSize         int      // maximum size of XML file we'll process
-	MaxFileSize        int      // maximum size of uploaded files in bytes
-	AllowedFileTypes   []string // allowed file types for upload (e.g. image/jpeg)
-	AllowUnknownFields bool     // if set to true, allow unknown fields in JSON
+	MaxJSONSize         int                          // maximum size of JSON file we'll process
+	MaxXMLSize          int                          // maximum size of XML file we'll process
+	MaxYAMLSize         int                          // maximum size of YAML file we'll process
+	MaxMsgPackSize      int                          // maximum size of MessagePack body we'll process
+	MaxProtoSize        int                          // maximum size of Protobuf body we'll process
+	MaxFileSize         int                          // maximum size of uploaded files in bytes
+	MaxUploadCount      int                          // maximum number of files in a single upload (zero means unlimited)
+	MaxImageWidth       int                          // maximum width in pixels of uploaded images (zero means unlimited)
+	MaxImageHeight      int                          // maximum height in pixels of uploaded images (zero means unlimited)
+	AllowedFileTypes    []string                     // allowed file types for upload (e.g. image/jpeg)
+	Checksum            bool                         // if set to true, compute a SHA-256 checksum of each uploaded file as it is saved
+	AllowUnknownFields  bool                         // if set to true, allow unknown fields in JSON
+	PrettyJSON          bool                         // if set to true, indent all JSON responses (useful in development)
+	TrustProxy          bool                         // if set to true and TrustedProxies is empty, treat every proxy as trusted by ClientIP (unsafe unless all traffic comes through your proxies)
+	TrustedProxies      []string                     // CIDRs or IPs of proxies whose X-Forwarded-For and X-Real-IP headers ClientIP trusts (e.g. 10.0.0.0/8)
+	Development         bool                         // if set to true, error responses may include internal details (never use in production)
+	CORS                CORSOptions                  // cross-origin settings used by EnableCORS
+	SecureHeadersConfig SecureHeadersConfig          // security header settings used by SecureHeaders
+	TemplateReload      bool                         // if set to true, RenderCached re-parses templates on every request (useful in development)
+	OnBodyTooLarge      func(r *http.Request)        // if set, called whenever a request body is rejected for being over its size limit (e.g. to log the client)
+	IdempotencyTTL      time.Duration                // how long the Idempotency middleware keeps responses (zero means 24 hours)
+	LogSkipPaths        []string                     // request paths LogRequests doesn't log (e.g. /health)
+	BcryptCost          int                          // bcrypt cost used by HashPassword (zero means 12)
+	BufferBody          bool                         // if set to true, ReadJSON reads the whole body before decoding, so a dropped connection returns ErrBodyInterrupted rather than a JSON error
+	CoerceJSONTypes     bool                         // if set to true, ReadJSON accepts numbers and bools sent as strings (e.g. "count": "5")
+	NormalizeStrings    bool                         // if set to true, ReadJSON trims whitespace from every string it decodes (except fields tagged `normalize:"preserve"`)
+	UseJSONNumber       bool                         // if set to true, ReadJSON decodes numbers in interface{} values as json.Number rather than float64, keeping large integers exact
+	RoutePattern        func(r *http.Request) string // if set, returns the route pattern r matched (e.g. "/users/{id}"), used to label metrics and key RateLimitWithStore
+
+	mu              sync.RWMutex                 // guards the fields below
+	errorStatuses   []errorStatus                // errors registered with RegisterErrorStatus, in registration order
+	translations    map[string]map[string]string // error message translations by language, from SetErrorTranslations
+	languages       []string                     // languages registered with SetErrorTranslations, in registration order
+	templateDir     string                       // directory last loaded by NewTemplateCache, re-parsed when TemplateReload is set
+	metrics         *metricsRegistry             // metrics recorded by Metrics, created on first use
+	commonPasswords map[string]bool              // blacklist set by SetCommonPasswords (nil means the default list)
+	timeFormats     []string                     // extra time layouts ReadJSON accepts, from RegisterTimeFormat
+	routeLabels     map[string]bool              // route labels derived from paths by routeLabel, capped at maxRouteLabels
+	proxies         *parsedProxies               // TrustedProxies as last parsed by ClientIP
 }
 
+// ErrBodyInterrupted is returned by ReadJSON, when BufferBody is set, if the connection fails before the whole
+// body has arrived, so it can be told apart from a body that arrived complete but holds malformed JSON.
+var ErrBodyInterrupted = errors.New("connection interrupted while reading the body")
+
 // JSONResponse is the type used for sending JSON around.
 type JSONResponse struct {
-	Error   bool        `json:"error"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+	Error     bool        `json:"error"`
+	Message   string      `json:"message"`
+	Code      string      `json:"code,omitempty"`       // optional machine-readable error code, e.g. invalid_email
+	RequestID string      `json:"request_id,omitempty"` // request ID set by the RequestID middleware, on errors
+	Data      interface{} `json:"data,omitempty"`
+	Meta      *Pagination `json:"meta,omitempty"` // pagination details, for list responses
 }
 
 // XMLResponse is the type used for sending XML around.
@@ -69,16 +120,28 @@ JSON Request/Response Utils
 // ReadJSON tries to read the body of a request and converts it from JSON to a variable. The third parameter, data,
 // is expected to be a pointer, so that we can read data into it.
 func (t *Tools) ReadJSON(w http.ResponseWriter, r *http.Request, data interface{}) error {
+	// Set a sensible default for the maximum payload size.
+	maxBytes := 1024 * 1024 // one megabyte
 
-	// Check content-type header; it should be application/json. If it's not specified,
-	// try to decode the body anyway.
-	if r.Header.Get("Content-Type") != "" {
-		contentType := r.Header.Get("Content-Type")
-		if strings.ToLower(contentType) != "application/json" {
-			return errors.New("the Content-Type header is not application/json")
-		}
+	// If MaxJSONSize is set, use that value instead of default.
+	if t.MaxJSONSize != 0 {
+		maxBytes = t.MaxJSONSize
 	}
 
+	return t.ReadJSONLimit(w, r, data, maxBytes)
+}
+
+// ReadJSONLimit behaves exactly like ReadJSON, but limits the body to maxBytes rather than MaxJSONSize. This is
+// useful for the odd endpoint, such as a webhook, that legitimately accepts much larger bodies than the rest.
+func (t *Tools) ReadJSONLimit(w http.ResponseWriter, r *http.Request, data interface{}, maxBytes int) error {
+	_, err := t.readJSON(w, r, data, maxBytes, false)
+	return err
+}
+
+// ReadJSONRaw behaves exactly like ReadJSON, but also returns the raw bytes of the body it decoded, so a
+// payload can be archived or forwarded as well as processed. The bytes are after any gzip decompression, and
+// are only returned once the size limit and single-value checks have passed.
+func (t *Tools) ReadJSONRaw(w http.ResponseWriter, r *http.Request, data interface{}) (json.RawMessage, error) {
 	// Set a sensible default for the maximum payload size.
 	maxBytes := 1024 * 1024 // one megabyte
 
@@ -86,62 +149,290 @@ func (t *Tools) ReadJSON(w http.ResponseWriter, r *http.Request, data interface{
 	if t.MaxJSONSize != 0 {
 		maxBytes = t.MaxJSONSize
 	}
+
+	body, err := t.readJSON(w, r, data, maxBytes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(body), nil
+}
+
+// readJSON does the work for ReadJSONLimit and ReadJSONRaw, decoding a body of at most maxBytes from r into
+// data. When keepRaw is set the body is always buffered, and the buffered bytes are returned.
+func (t *Tools) readJSON(w http.ResponseWriter, r *http.Request, data interface{}, maxBytes int, keepRaw bool) ([]byte, error) {
+
+	// Check content-type header; it should be application/json. If it's not specified,
+	// try to decode the body anyway.
+	if r.Header.Get("Content-Type") != "" {
+		contentType := r.Header.Get("Content-Type")
+		if strings.ToLower(contentType) != "application/json" {
+			return nil, errors.New("the Content-Type header is not application/json")
+		}
+	}
+
+	err := gunzipBody(r)
+	if err != nil {
+		return nil, err
+	}
+
 	r.Body = http.MaxBytesReader(w, r.Body, int64(maxBytes))
 
-	dec := json.NewDecoder(r.Body)
+	// Read the whole body up front when asked to, or when coercing types or parsing registered time formats,
+	// which need a second look at it. Any read error is then a problem with the connection rather than with
+	// the JSON.
+	timeFormats := t.registeredTimeFormats()
+	var src io.Reader = r.Body
+	var body []byte
+	if keepRaw || t.BufferBody || t.CoerceJSONTypes || len(timeFormats) > 0 {
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			switch {
+			case isGzipError(err):
+				return nil, errors.New("malformed gzip body")
+			case err.Error() == "http: request body too large":
+				return nil, t.bodyTooLarge(r, maxBytes)
+			default:
+				return nil, ErrBodyInterrupted
+			}
+		}
+		src = bytes.NewReader(body)
+	}
+
+	dec := json.NewDecoder(src)
 
 	// Should we allow unknown fields?
 	if !t.AllowUnknownFields {
 		dec.DisallowUnknownFields()
 	}
 
+	// Should numbers in interface{} values stay as json.Number? This keeps integers above 2^53 exact, but
+	// callers then have to use Int64, Float64 or String on them, as a float64 type assertion will fail.
+	if t.UseJSONNumber {
+		dec.UseNumber()
+	}
+
 	// Attempt to decode the data, and figure out what the error is, if any, to send back a human-readable
 	// response.
-	err := dec.Decode(data)
+	err = dec.Decode(data)
+
+	// If a client sent a number or bool wrapped in a string, or a time in a registered format, and we've
+	// been asked to be lenient, try again with those values converted.
+	var coercibleError *json.UnmarshalTypeError
+	var timeError *time.ParseError
+	if err != nil && ((t.CoerceJSONTypes && errors.As(err, &coercibleError)) || (len(timeFormats) > 0 && errors.As(err, &timeError))) {
+		dec, err = t.decodeLenient(body, data, timeFormats)
+	}
+
 	if err != nil {
 		var syntaxError *json.SyntaxError
 		var unmarshalTypeError *json.UnmarshalTypeError
 		var invalidUnmarshalError *json.InvalidUnmarshalError
 
 		switch {
+		case isGzipError(err):
+			return nil, errors.New("malformed gzip body")
+
 		case errors.As(err, &syntaxError):
-			return fmt.Errorf("body contains badly-formed JSON (at character %d)", syntaxError.Offset)
+			return nil, fmt.Errorf("body contains badly-formed JSON (at character %d)", syntaxError.Offset)
 
 		case errors.Is(err, io.ErrUnexpectedEOF):
-			return errors.New("body contains badly-formed JSON")
+			return nil, errors.New("body contains badly-formed JSON")
 
 		case errors.As(err, &unmarshalTypeError):
-			return fmt.Errorf("body contains incorrect JSON type for field %q at offset %d", unmarshalTypeError.Field, unmarshalTypeError.Offset)
+			return nil, fmt.Errorf("body contains incorrect JSON type for field %q at offset %d", unmarshalTypeError.Field, unmarshalTypeError.Offset)
 
 		case errors.Is(err, io.EOF):
-			return errors.New("body must not be empty")
+			return nil, errors.New("body must not be empty")
 
 		case strings.HasPrefix(err.Error(), "json: unknown field "):
 			fieldName := strings.TrimPrefix(err.Error(), "json: unknown field ")
-			return fmt.Errorf("body contains unknown key %s", fieldName)
+			return nil, fmt.Errorf("body contains unknown key %s", fieldName)
 
 		case err.Error() == "http: request body too large":
-			return fmt.Errorf("body must not be larger than %d bytes", maxBytes)
+			return nil, t.bodyTooLarge(r, maxBytes)
 
 		case errors.As(err, &invalidUnmarshalError):
-			return fmt.Errorf("error unmarshalling json: %s", err.Error())
+			return nil, fmt.Errorf("error unmarshalling json: %s", err.Error())
 
 		default:
-			return err
+			return nil, err
 		}
 	}
 
 	err = dec.Decode(&struct{}{})
 	if err != io.EOF {
-		return errors.New("body must only contain a single JSON value")
+		// A corrupt gzip trailer only shows up once the decompressor reaches the end of the stream.
+		if isGzipError(err) {
+			return nil, errors.New("malformed gzip body")
+		}
+		return nil, errors.New("body must only contain a single JSON value")
+	}
+
+	if t.NormalizeStrings {
+		normalizeStrings(reflect.ValueOf(data))
 	}
 
+	return body, nil
+}
+
+// readJSONBody reads the whole body of a request, limited to MaxJSONSize, and checks that it holds valid
+// JSON, for helpers that work on the raw bytes rather than decoding into a variable.
+func (t *Tools) readJSONBody(r *http.Request) ([]byte, error) {
+	// Set a sensible default for the maximum payload size.
+	maxBytes := 1024 * 1024 // one megabyte
+
+	// If MaxJSONSize is set, use that value instead of default.
+	if t.MaxJSONSize != 0 {
+		maxBytes = t.MaxJSONSize
+	}
+
+	err := gunzipBody(r)
+	if err != nil {
+		return nil, err
+	}
+	r.Body = http.MaxBytesReader(nil, r.Body, int64(maxBytes))
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		switch {
+		case isGzipError(err):
+			return nil, errors.New("malformed gzip body")
+		case err.Error() == "http: request body too large":
+			return nil, t.bodyTooLarge(r, maxBytes)
+		default:
+			return nil, err
+		}
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, errors.New("body must not be empty")
+	}
+	if !json.Valid(body) {
+		return nil, errors.New("body contains badly-formed JSON")
+	}
+
+	return body, nil
+}
+
+// gunzipBody transparently decompresses a gzip-encoded request body, replacing r.Body with the decompressed
+// stream. Size limits should be applied afterwards, to the decompressed stream, so a small, highly compressed
+// body can't be used to exhaust memory. The Content-Encoding header is removed once the body is replaced, so
+// helpers that read the body and then hand it on to ReadJSON don't try to decompress it twice.
+func gunzipBody(r *http.Request) error {
+	if !strings.EqualFold(strings.TrimSpace(r.Header.Get("Content-Encoding")), "gzip") {
+		return nil
+	}
+
+	gz, err := gzip.NewReader(r.Body)
+	if err != nil {
+		return errors.New("malformed gzip body")
+	}
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{gz, r.Body}
+	r.Header.Del("Content-Encoding")
+	r.ContentLength = -1
+
 	return nil
 }
 
+// bodyTooLarge calls the OnBodyTooLarge hook, if one is set, and returns the error sent when a body from r is
+// over the maxBytes limit.
+func (t *Tools) bodyTooLarge(r *http.Request, maxBytes int) error {
+	if t.OnBodyTooLarge != nil {
+		t.OnBodyTooLarge(r)
+	}
+
+	return fmt.Errorf("body must not be larger than %d bytes", maxBytes)
+}
+
+// isGzipError reports whether err came from decompressing a malformed gzip stream.
+func isGzipError(err error) bool {
+	var corruptInputError flate.CorruptInputError
+	return errors.Is(err, gzip.ErrHeader) || errors.Is(err, gzip.ErrChecksum) || errors.As(err, &corruptInputError)
+}
+
 // WriteJSON takes a response status code and arbitrary data and writes a JSON response to the client.
 func (t *Tools) WriteJSON(w http.ResponseWriter, status int, data interface{}, headers ...http.Header) error {
-	out, err := json.Marshal(data)
+	out, err := t.marshalJSON(data)
+	if err != nil {
+		return err
+	}
+
+	// If we have a value as the last parameter in the function call, then we are setting a custom header.
+	if len(headers) > 0 {
+		for key, value := range headers[0] {
+			w.Header()[key] = value
+		}
+	}
+
+	// Set the content type and send response.
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(out)
+
+	return nil
+}
+
+// WriteJSONIndent behaves like WriteJSON, but always indents the JSON with two spaces so it is readable from
+// tools like curl. Set PrettyJSON on Tools to indent every WriteJSON response instead.
+func (t *Tools) WriteJSONIndent(w http.ResponseWriter, status int, data interface{}, headers ...http.Header) error {
+	out, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// If we have a value as the last parameter in the function call, then we are setting a custom header.
+	if len(headers) > 0 {
+		for key, value := range headers[0] {
+			w.Header()[key] = value
+		}
+	}
+
+	// Set the content type and send response.
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(out)
+
+	return nil
+}
+
+// WriteJSONFromReader writes JSON that has already been serialized, e.g. cached in Redis or read from disk,
+// straight from r to the client, saving a needless unmarshal and marshal. The JSON is not validated; that is
+// left to the caller.
+func (t *Tools) WriteJSONFromReader(w http.ResponseWriter, status int, r io.Reader, headers ...http.Header) error {
+	// If we have a value as the last parameter in the function call, then we are setting a custom header.
+	if len(headers) > 0 {
+		for key, value := range headers[0] {
+			w.Header()[key] = value
+		}
+	}
+
+	// Set the content type and send response.
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err := io.Copy(w, r)
+
+	return err
+}
+
+// marshalJSON marshals data for a response, indenting it if PrettyJSON is set.
+func (t *Tools) marshalJSON(data interface{}) ([]byte, error) {
+	if t.PrettyJSON {
+		return json.MarshalIndent(data, "", "  ")
+	}
+	return json.Marshal(data)
+}
+
+// gzipMinSize is the smallest JSON body, in bytes, that WriteJSONCompressed will bother to compress.
+const gzipMinSize = 1024
+
+// WriteJSONCompressed behaves like WriteJSON, but gzip-compresses the body when the request's Accept-Encoding
+// header allows it. Bodies smaller than gzipMinSize are sent uncompressed, as compressing them isn't worth it.
+func (t *Tools) WriteJSONCompressed(w http.ResponseWriter, r *http.Request, status int, data interface{}, headers ...http.Header) error {
+	out, err := t.marshalJSON(data)
 	if err != nil {
 		return err
 	}
@@ -153,6 +444,83 @@ func (t *Tools) WriteJSON(w http.ResponseWriter, status int, data interface{}, h
 		}
 	}
 
+	// The response differs with Accept-Encoding, so caches must key on it.
+	w.Header().Add("Vary", "Accept-Encoding")
+	w.Header().Set("Content-Type", "application/json")
+
+	if len(out) < gzipMinSize || !acceptsGzip(r) {
+		w.WriteHeader(status)
+		_, _ = w.Write(out)
+		return nil
+	}
+
+	w.Header().Set("Content-Encoding", "gzip")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(status)
+
+	gz := gzip.NewWriter(w)
+	_, err = gz.Write(out)
+	if err != nil {
+		_ = gz.Close()
+		return err
+	}
+
+	// Closing flushes the remaining compressed data and writes the gzip footer.
+	return gz.Close()
+}
+
+// acceptsGzip reports whether the request's Accept-Encoding header allows a gzip-encoded response.
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		fields := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(fields[0]))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+
+		// An explicit q=0 means the client refuses this encoding.
+		q := 1.0
+		for _, param := range fields[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.ToLower(strings.TrimSpace(key)) == "q" {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WriteJSONCached behaves like WriteJSON, but also sets a weak ETag computed from the response body. If the
+// request's If-None-Match header already holds that ETag, a 304 Not Modified is sent without a body instead.
+// Only successful (200) responses to GET and HEAD requests are ever answered with a 304.
+func (t *Tools) WriteJSONCached(w http.ResponseWriter, r *http.Request, status int, data interface{}, headers ...http.Header) error {
+	out, err := t.marshalJSON(data)
+	if err != nil {
+		return err
+	}
+
+	// If we have a value as the last parameter in the function call, then we are setting a custom header.
+	if len(headers) > 0 {
+		for key, value := range headers[0] {
+			w.Header()[key] = value
+		}
+	}
+
+	sum := sha256.Sum256(out)
+	etag := fmt.Sprintf(`W/"%s"`, hex.EncodeToString(sum[:16]))
+	w.Header().Set("ETag", etag)
+
+	if status == http.StatusOK && (r.Method == http.MethodGet || r.Method == http.MethodHead) && etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
 	// Set the content type and send response.
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -161,6 +529,49 @@ func (t *Tools) WriteJSON(w http.ResponseWriter, status int, data interface{}, h
 	return nil
 }
 
+// etagMatches reports whether an If-None-Match header matches etag, using the weak comparison the spec
+// requires for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == want {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NoContent sends a 204 No Content response, with any custom headers, and no body or Content-Type. Use it
+// instead of WriteJSON with nil data, which would send a body of null.
+func (t *Tools) NoContent(w http.ResponseWriter, headers ...http.Header) {
+	// If we have a value as the last parameter in the function call, then we are setting a custom header.
+	if len(headers) > 0 {
+		for key, value := range headers[0] {
+			w.Header()[key] = value
+		}
+	}
+
+	w.Header().Del("Content-Type")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Created sends a 201 Created JSON response for a newly created resource, with the Location header set to
+// location, the resource's URL.
+func (t *Tools) Created(w http.ResponseWriter, location string, data interface{}) error {
+	if location == "" {
+		return errors.New("location must not be empty")
+	}
+
+	w.Header().Set("Location", location)
+
+	return t.WriteJSON(w, http.StatusCreated, data)
+}
+
 // ErrorJSON takes an error, and optionally a response status code, and generates and sends
 // a JSON error response.
 func (t *Tools) ErrorJSON(w http.ResponseWriter, err error, status ...int) error {
@@ -175,6 +586,50 @@ func (t *Tools) ErrorJSON(w http.ResponseWriter, err error, status ...int) error
 	var payload JSONResponse
 	payload.Error = true
 	payload.Message = err.Error()
+	payload.RequestID = w.Header().Get(RequestIDHeader)
+
+	return t.WriteJSON(w, statusCode, payload)
+}
+
+// ErrorJSONWithCode behaves like ErrorJSON, but also sends a stable, machine-readable error code (e.g.
+// "invalid_email") in the payload, so clients can branch on the error without matching the message.
+func (t *Tools) ErrorJSONWithCode(w http.ResponseWriter, err error, code string, status ...int) error {
+	statusCode := http.StatusBadRequest
+
+	// If a custom response code is specified, use that instead of bad request.
+	if len(status) > 0 {
+		statusCode = status[0]
+	}
+
+	// Build the JSON payload.
+	var payload JSONResponse
+	payload.Error = true
+	payload.Message = err.Error()
+	payload.RequestID = w.Header().Get(RequestIDHeader)
+	payload.Code = code
 
 	return t.WriteJSON(w, statusCode, payload)
 }
+
+// ServerErrorJSON logs err at error level, along with the request ID if the RequestID middleware set one, and
+// then sends a 500 JSON error response with a generic message, so internal error details stay in the logs
+// and are never shown to the client. If logger is nil, the request's logger from the WithLogger middleware is
+// used, or slog.Default() if there isn't one.
+func (t *Tools) ServerErrorJSON(w http.ResponseWriter, err error, logger *slog.Logger) error {
+	if logger == nil {
+		logger = loggerFromWriter(w)
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	// The request's own logger from WithLogger already carries the request ID.
+	attrs := []any{"error", err.Error()}
+	if logger != loggerFromWriter(w) {
+		attrs = append(attrs, "request_id", w.Header().Get(RequestIDHeader))
+	}
+
+	logger.Error("server error", attrs...)
+
+	return t.ErrorJSON(w, errors.New("the server encountered a problem and could not process your request"), http.StatusInternalServerError)
+}
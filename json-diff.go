@@ -0,0 +1,125 @@
+package goMicroServiceUtils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+/*
+=================================================================================
+JSON Diff Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// JSONDiff compares two JSON documents and returns a structured diff with three keys, "added", "removed" and
+// "changed", each a map keyed by the JSON Pointer of the field. Added and removed entries hold the value that
+// appeared or disappeared; changed entries hold a map with "from" and "to". Objects are diffed recursively,
+// arrays are compared position by position, and numbers are compared by value, so 1 and 1.0 are equal. A
+// value whose type changes (an object becoming a string, say) is reported as changed. The whole document is
+// at the pointer "".
+func (t *Tools) JSONDiff(before, after []byte) (map[string]interface{}, error) {
+	var beforeDoc, afterDoc interface{}
+
+	err := decodeJSONNumbers(before, &beforeDoc)
+	if err != nil {
+		return nil, fmt.Errorf("before is not valid JSON: %s", err.Error())
+	}
+	err = decodeJSONNumbers(after, &afterDoc)
+	if err != nil {
+		return nil, fmt.Errorf("after is not valid JSON: %s", err.Error())
+	}
+
+	added := make(map[string]interface{})
+	removed := make(map[string]interface{})
+	changed := make(map[string]interface{})
+
+	diffJSONValues("", beforeDoc, afterDoc, added, removed, changed)
+
+	return map[string]interface{}{
+		"added":   added,
+		"removed": removed,
+		"changed": changed,
+	}, nil
+}
+
+// diffJSONValues records the differences between the decoded values a and b, found at path, into added,
+// removed and changed.
+func diffJSONValues(path string, a, b interface{}, added, removed, changed map[string]interface{}) {
+	switch aNode := a.(type) {
+	case map[string]interface{}:
+		bNode, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for key, aValue := range aNode {
+			childPath := path + "/" + strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+			bValue, ok := bNode[key]
+			if !ok {
+				removed[childPath] = aValue
+				continue
+			}
+			diffJSONValues(childPath, aValue, bValue, added, removed, changed)
+		}
+		for key, bValue := range bNode {
+			if _, ok := aNode[key]; !ok {
+				added[path+"/"+strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")] = bValue
+			}
+		}
+		return
+
+	case []interface{}:
+		bNode, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(aNode) || i < len(bNode); i++ {
+			childPath := path + "/" + strconv.Itoa(i)
+			switch {
+			case i >= len(bNode):
+				removed[childPath] = aNode[i]
+			case i >= len(aNode):
+				added[childPath] = bNode[i]
+			default:
+				diffJSONValues(childPath, aNode[i], bNode[i], added, removed, changed)
+			}
+		}
+		return
+	}
+
+	if !jsonScalarsEqual(a, b) {
+		changed[path] = map[string]interface{}{"from": a, "to": b}
+	}
+}
+
+// jsonScalarsEqual reports whether a and b are the same decoded JSON value. Objects and arrays are never
+// equal to anything here, since they are diffed by diffJSONValues before reaching this point.
+func jsonScalarsEqual(a, b interface{}) bool {
+	switch aValue := a.(type) {
+	case nil:
+		return b == nil
+	case bool:
+		bValue, ok := b.(bool)
+		return ok && aValue == bValue
+	case string:
+		bValue, ok := b.(string)
+		return ok && aValue == bValue
+	case json.Number:
+		bValue, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		if aValue == bValue {
+			return true
+		}
+		aRat, aOK := new(big.Rat).SetString(aValue.String())
+		bRat, bOK := new(big.Rat).SetString(bValue.String())
+		return aOK && bOK && aRat.Cmp(bRat) == 0
+	}
+
+	return false
+}
@@ -0,0 +1,97 @@
+package goMicroServiceUtils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+/*
+=================================================================================
+JSON Patch Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// ApplyMergePatch applies an RFC 7396 JSON merge patch to original and returns the patched document. Keys set
+// to null in the patch are removed, objects are merged recursively, and any other value, including an array,
+// replaces the original value outright.
+func (t *Tools) ApplyMergePatch(original, patch []byte) ([]byte, error) {
+	var patchDoc interface{}
+	err := decodeJSONNumbers(patch, &patchDoc)
+	if err != nil {
+		return nil, fmt.Errorf("patch is not valid JSON: %s", err.Error())
+	}
+
+	// An empty original is treated as null, so the patch becomes the whole document.
+	var originalDoc interface{}
+	if len(bytes.TrimSpace(original)) > 0 {
+		err = decodeJSONNumbers(original, &originalDoc)
+		if err != nil {
+			return nil, fmt.Errorf("original document is not valid JSON: %s", err.Error())
+		}
+	}
+
+	return json.Marshal(mergePatch(originalDoc, patchDoc))
+}
+
+// ReadMergePatch reads a JSON merge patch from the body of a request, limited to MaxJSONSize, ready to be
+// passed to ApplyMergePatch. The Content-Type, if set, must be application/merge-patch+json or
+// application/json.
+func (t *Tools) ReadMergePatch(r *http.Request) ([]byte, error) {
+	if r.Header.Get("Content-Type") != "" {
+		contentType := strings.ToLower(strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0]))
+		if contentType != "application/merge-patch+json" && contentType != "application/json" {
+			return nil, errors.New("the Content-Type header is not application/merge-patch+json")
+		}
+	}
+
+	return t.readJSONBody(r)
+}
+
+// mergePatch applies patch to target following the algorithm in RFC 7396.
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = mergePatch(targetObj[key], value)
+	}
+
+	return targetObj
+}
+
+// decodeJSONNumbers unmarshals a single JSON value from data into v, keeping numbers as json.Number so
+// large integers survive a round trip without losing precision.
+func decodeJSONNumbers(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	err := dec.Decode(v)
+	if err != nil {
+		return err
+	}
+
+	err = dec.Decode(&struct{}{})
+	if err != io.EOF {
+		return errors.New("must only contain a single JSON value")
+	}
+
+	return nil
+}
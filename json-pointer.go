@@ -0,0 +1,80 @@
+package goMicroServiceUtils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+/*
+=================================================================================
+JSON Pointer Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// ReadJSONPointer reads a JSON body, limited to MaxJSONSize, and returns just the value found at pointer, an
+// RFC 6901 JSON Pointer such as "/data/items/0/id". This is handy for pulling one field out of a large
+// payload, like a webhook, without defining a struct for the whole document. An empty pointer returns the
+// whole document.
+func (t *Tools) ReadJSONPointer(r *http.Request, pointer string) (json.RawMessage, error) {
+	if pointer != "" && !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("json pointer %q must be empty or start with /", pointer)
+	}
+
+	body, err := t.readJSONBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc interface{}
+	err = decodeJSONNumbers(body, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("body contains badly-formed JSON: %s", err.Error())
+	}
+
+	if pointer == "" {
+		return json.RawMessage(body), nil
+	}
+
+	current := doc
+	path := ""
+	for _, token := range strings.Split(pointer[1:], "/") {
+		// Unescape in this order, so "~01" becomes "~1" rather than "/".
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("json pointer %q does not resolve: no key %q at %q", pointer, token, path)
+			}
+			current = value
+
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || (len(token) > 1 && token[0] == '0') {
+				return nil, fmt.Errorf("json pointer %q does not resolve: %q is not a valid array index at %q", pointer, token, path)
+			}
+			if index >= len(node) {
+				return nil, fmt.Errorf("json pointer %q does not resolve: index %d is out of range at %q", pointer, index, path)
+			}
+			current = node[index]
+
+		default:
+			return nil, fmt.Errorf("json pointer %q does not resolve: the value at %q is not an object or array", pointer, path)
+		}
+
+		path += "/" + strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+	}
+
+	out, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.RawMessage(out), nil
+}
@@ -0,0 +1,113 @@
+package goMicroServiceUtils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+/*
+=================================================================================
+JSON Schema Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// Schema is a compiled JSON Schema, created once with Tools.CompileSchema and safe to share between requests.
+type Schema struct {
+	schema *jsonschema.Schema
+}
+
+/*
+=================================================================================
+JSON Schema Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// CompileSchema compiles a JSON Schema document, so it can be reused for every request rather than being
+// parsed each time. Schemas without a $schema keyword are treated as draft 2020-12.
+func (t *Tools) CompileSchema(schema []byte) (*Schema, error) {
+	compiler := jsonschema.NewCompiler()
+
+	err := compiler.AddResource("mem:///schema.json", bytes.NewReader(schema))
+	if err != nil {
+		return nil, fmt.Errorf("error reading schema: %s", err.Error())
+	}
+
+	compiled, err := compiler.Compile("mem:///schema.json")
+	if err != nil {
+		return nil, fmt.Errorf("error compiling schema: %s", err.Error())
+	}
+
+	return &Schema{schema: compiled}, nil
+}
+
+// ReadJSONSchema validates a JSON body against schema and then, if it's valid, reads it into data exactly as
+// ReadJSON would. When the body breaks the schema, every violation is returned as FieldErrors, keyed by the
+// JSON Pointer of the offending value (e.g. "/address/postcode", or "/" for the body itself).
+func (t *Tools) ReadJSONSchema(w http.ResponseWriter, r *http.Request, schema *Schema, data interface{}) error {
+	if schema == nil {
+		return errors.New("schema must not be nil")
+	}
+
+	body, err := t.readJSONBody(r)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc interface{}
+	err = dec.Decode(&doc)
+	if err != nil {
+		return errors.New("body contains badly-formed JSON")
+	}
+
+	err = schema.schema.Validate(doc)
+	if err != nil {
+		var validationError *jsonschema.ValidationError
+		if !errors.As(err, &validationError) {
+			return err
+		}
+
+		fieldErrors := make(FieldErrors)
+		collectSchemaErrors(validationError, fieldErrors)
+		return fieldErrors
+	}
+
+	// The body has already been read, so hand ReadJSON a fresh copy of it.
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return t.ReadJSON(w, r, data)
+}
+
+// collectSchemaErrors adds the leaf violations under ve to fieldErrors. Several violations of the same value
+// are joined into one message.
+func collectSchemaErrors(ve *jsonschema.ValidationError, fieldErrors FieldErrors) {
+	if len(ve.Causes) > 0 {
+		for _, cause := range ve.Causes {
+			collectSchemaErrors(cause, fieldErrors)
+		}
+		return
+	}
+
+	location := ve.InstanceLocation
+	if location == "" {
+		location = "/"
+	}
+	if existing, ok := fieldErrors[location]; ok {
+		if !strings.Contains(existing, ve.Message) {
+			fieldErrors[location] = existing + ", " + ve.Message
+		}
+		return
+	}
+	fieldErrors[location] = ve.Message
+}
@@ -0,0 +1,154 @@
+package goMicroServiceUtils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+/*
+=================================================================================
+JSON Stream Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// JSONStreamError is returned by ReadJSONStream when reading stops early, and records how many records were
+// processed successfully before the failure.
+type JSONStreamError struct {
+	Records int   // number of records successfully passed to the callback before the error
+	Err     error // the decode or callback error that stopped the stream
+}
+
+// Error implements the error interface.
+func (e *JSONStreamError) Error() string {
+	return fmt.Sprintf("stream stopped after %d records: %s", e.Records, e.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (e *JSONStreamError) Unwrap() error {
+	return e.Err
+}
+
+/*
+=================================================================================
+JSON Stream Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// ReadJSONStream reads a stream of JSON values, such as newline-delimited JSON, from the body of a request,
+// calling fn with each one in turn. Values are decoded one at a time, so the whole body is never held in
+// memory, but the total body size is still capped by MaxJSONSize. Reading stops at the first error returned
+// by fn or met while decoding, and is reported as a *JSONStreamError.
+func (t *Tools) ReadJSONStream(r *http.Request, fn func(raw json.RawMessage) error) error {
+	// Set a sensible default for the maximum payload size.
+	maxBytes := 1024 * 1024 // one megabyte
+
+	// If MaxJSONSize is set, use that value instead of default.
+	if t.MaxJSONSize != 0 {
+		maxBytes = t.MaxJSONSize
+	}
+	r.Body = http.MaxBytesReader(nil, r.Body, int64(maxBytes))
+
+	dec := json.NewDecoder(r.Body)
+
+	records := 0
+	for {
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			var syntaxError *json.SyntaxError
+
+			switch {
+			case errors.As(err, &syntaxError):
+				err = fmt.Errorf("body contains badly-formed JSON (at character %d)", syntaxError.Offset)
+
+			case errors.Is(err, io.ErrUnexpectedEOF):
+				err = errors.New("body contains badly-formed JSON")
+
+			case err.Error() == "http: request body too large":
+				err = t.bodyTooLarge(r, maxBytes)
+			}
+
+			return &JSONStreamError{Records: records, Err: err}
+		}
+
+		err = fn(raw)
+		if err != nil {
+			return &JSONStreamError{Records: records, Err: err}
+		}
+		records++
+	}
+}
+
+// streamFlushEvery is how many array elements StreamJSONArray writes between flushes.
+const streamFlushEvery = 100
+
+// StreamJSONArray writes a JSON array to the client one element at a time, as items are received from the
+// channel, so a very large result never has to be held in memory. The array is closed once the channel is
+// closed. If an item can't be encoded part way through, the array is still closed, so the client gets valid
+// JSON, and the error is returned; by then the status and headers have already been sent. Whenever it returns
+// early, including when a write fails because the client has gone away, the rest of the channel is drained in
+// the background, so the producer is never left blocked on a send.
+func (t *Tools) StreamJSONArray(w http.ResponseWriter, status int, items <-chan interface{}) error {
+	// Flushing is best effort, so a writer that can't flush still gets the whole array, just not in pieces.
+	rc := http.NewResponseController(w)
+
+	// drain keeps receiving from the channel until the producer closes it.
+	drain := func() {
+		go func() {
+			for range items {
+			}
+		}()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_, err := w.Write([]byte("["))
+	if err != nil {
+		drain()
+		return err
+	}
+
+	count := 0
+	for item := range items {
+		out, err := json.Marshal(item)
+		if err != nil {
+			_, _ = w.Write([]byte("]"))
+			drain()
+			return fmt.Errorf("error encoding array element %d: %s", count, err.Error())
+		}
+
+		if count > 0 {
+			_, err = w.Write([]byte(","))
+			if err != nil {
+				drain()
+				return err
+			}
+		}
+		_, err = w.Write(out)
+		if err != nil {
+			drain()
+			return err
+		}
+
+		count++
+		if count%streamFlushEvery == 0 {
+			_ = rc.Flush()
+		}
+	}
+
+	_, err = w.Write([]byte("]"))
+	_ = rc.Flush()
+
+	return err
+}
@@ -0,0 +1,139 @@
+package goMicroServiceUtils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+/*
+=================================================================================
+JWT Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// ErrInvalidToken is returned by ParseJWT when a token is malformed, uses an unsupported algorithm, or has a
+// signature that doesn't match.
+var ErrInvalidToken = errors.New("invalid token")
+
+// ErrTokenExpired is returned by ParseJWT when a token's exp claim is in the past.
+var ErrTokenExpired = errors.New("token has expired")
+
+// jwtHeader is the JOSE header of a token.
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+}
+
+/*
+=================================================================================
+JWT Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// GenerateJWT returns an HS256-signed JSON Web Token carrying claims. The iat and exp claims are always set,
+// with exp ttl after now, so tokens can't accidentally be issued without an expiry.
+func (t *Tools) GenerateJWT(claims map[string]interface{}, secret []byte, ttl time.Duration) (string, error) {
+	if len(secret) == 0 {
+		return "", errors.New("a secret is required to sign a token")
+	}
+
+	// Copy the claims, so the caller's map isn't modified.
+	now := time.Now()
+	payload := make(map[string]interface{}, len(claims)+2)
+	for key, value := range claims {
+		payload[key] = value
+	}
+	payload["iat"] = now.Unix()
+	payload["exp"] = now.Add(ttl).Unix()
+
+	header, err := json.Marshal(jwtHeader{Alg: "HS256", Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(body)
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signJWT(signingInput, secret)), nil
+}
+
+// ParseJWT verifies an HS256-signed JSON Web Token and returns its claims. Tokens using any other algorithm,
+// including "none", or with a bad signature return ErrInvalidToken; expired tokens return ErrTokenExpired.
+// Numeric claims are returned as json.Number.
+func (t *Tools) ParseJWT(token string, secret []byte) (map[string]interface{}, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("a secret is required to verify a token")
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var header jwtHeader
+	err = json.Unmarshal(headerJSON, &header)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	// Only HS256 is accepted. Checking this before the signature stops "none" and algorithm-confusion tricks.
+	if header.Alg != "HS256" {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if !hmac.Equal(signature, signJWT(parts[0]+"."+parts[1], secret)) {
+		return nil, ErrInvalidToken
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var claims map[string]interface{}
+	err = dec.Decode(&claims)
+	if err != nil || claims == nil {
+		return nil, ErrInvalidToken
+	}
+
+	// Every token must carry an expiry, and it must be in the future.
+	exp, ok := claims["exp"].(json.Number)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	expSeconds, err := exp.Float64()
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if float64(time.Now().Unix()) >= expSeconds {
+		return nil, ErrTokenExpired
+	}
+
+	return claims, nil
+}
+
+// signJWT returns the HMAC-SHA256 of signingInput using secret.
+func signJWT(signingInput string, secret []byte) []byte {
+	return signHMAC([]byte(signingInput), secret)
+}
@@ -0,0 +1,126 @@
+package goMicroServiceUtils
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+/*
+=================================================================================
+Logging Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// LoggerContextKey is the request context key the WithLogger middleware stores the request's logger under.
+const LoggerContextKey contextKey = "logger"
+
+// loggerWriter carries the request's logger on the ResponseWriter, so ServerErrorJSON, which is only given
+// the writer, can find it.
+type loggerWriter struct {
+	*StatusRecorder
+	logger *slog.Logger
+}
+
+/*
+=================================================================================
+Logging Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// LogRequests returns middleware that writes an access log entry to logger for every request, with its
+// method, path, status, bytes written, duration, client IP (see ClientIP) and request ID (when the RequestID
+// middleware is in use). Requests to any of the paths in LogSkipPaths, such as health checks, aren't logged.
+// If logger is nil, slog.Default() is used.
+func (t *Tools) LogRequests(logger *slog.Logger) func(http.Handler) http.Handler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	skip := make(map[string]bool, len(t.LogSkipPaths))
+	for _, path := range t.LogSkipPaths {
+		skip[path] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skip[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			rec := t.NewStatusRecorder(w)
+
+			next.ServeHTTP(rec, r)
+
+			// The RequestID middleware may wrap this one either way round, so check the response header too.
+			requestID := t.RequestIDFromContext(r.Context())
+			if requestID == "" {
+				requestID = w.Header().Get(RequestIDHeader)
+			}
+
+			logger.Info("request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", rec.StatusCode,
+				"bytes", rec.BytesWritten,
+				"duration", time.Since(start),
+				"client_ip", t.ClientIP(r),
+				"request_id", requestID,
+			)
+		})
+	}
+}
+
+// WithLogger returns middleware that gives each request its own logger, derived from base with the request's
+// method, path and request ID (when the RequestID middleware runs first) attached, so handlers don't have to
+// add them to every log call. Handlers fetch it with LoggerFromContext, and ServerErrorJSON uses it when it
+// isn't given a logger. If base is nil, slog.Default() is used.
+func (t *Tools) WithLogger(base *slog.Logger) func(http.Handler) http.Handler {
+	if base == nil {
+		base = slog.Default()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := base.With("method", r.Method, "path", r.URL.Path)
+			if requestID := t.RequestIDFromContext(r.Context()); requestID != "" {
+				logger = logger.With("request_id", requestID)
+			}
+
+			ctx := context.WithValue(r.Context(), LoggerContextKey, logger)
+			next.ServeHTTP(&loggerWriter{StatusRecorder: t.NewStatusRecorder(w), logger: logger}, r.WithContext(ctx))
+		})
+	}
+}
+
+// LoggerFromContext returns the request's logger stored by the WithLogger middleware, or slog.Default() if
+// there isn't one, so it is always safe to log with.
+func (t *Tools) LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(LoggerContextKey).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+	return slog.Default()
+}
+
+// loggerFromWriter returns the logger WithLogger attached to w, looking through any writers wrapped around it
+// that support Unwrap, such as StatusRecorder, or nil if there isn't one.
+func loggerFromWriter(w http.ResponseWriter) *slog.Logger {
+	for w != nil {
+		if lw, ok := w.(*loggerWriter); ok {
+			return lw.logger
+		}
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return nil
+		}
+		w = unwrapper.Unwrap()
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package goMicroServiceUtils
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+=================================================================================
+Metrics Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// metricsBuckets are the upper bounds, in seconds, of the request latency histogram buckets. They match the
+// Prometheus client's defaults, so dashboards built for other services work unchanged.
+var metricsBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// idSegment matches path segments that are almost certainly IDs: numbers, UUIDs and long hex strings.
+var idSegment = regexp.MustCompile(`^([0-9]+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{16,})$`)
+
+// maxRouteLabels caps how many distinct routes routeLabel derives from request paths when RoutePattern isn't
+// set. Any further routes share otherRoute, so unexpected paths can't grow memory or the metrics output
+// without bound.
+const maxRouteLabels = 100
+
+// otherRoute is the route label for requests that didn't match a route, and for paths beyond maxRouteLabels.
+const otherRoute = "other"
+
+// otherMethod is the method label for requests with a non-standard method.
+const otherMethod = "OTHER"
+
+// standardMethods are the request methods that get their own metrics label.
+var standardMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// routeKey identifies a route for the latency histogram.
+type routeKey struct {
+	method string
+	route  string
+}
+
+// requestKey identifies a route and response status for the request counter.
+type requestKey struct {
+	routeKey
+	status int
+}
+
+// latencyHistogram counts request durations into metricsBuckets.
+type latencyHistogram struct {
+	buckets []uint64 // count of observations in each bucket, not cumulative
+	sum     float64
+	count   uint64
+}
+
+// metricsRegistry holds the metrics recorded by the Metrics middleware.
+type metricsRegistry struct {
+	mu        sync.Mutex
+	requests  map[requestKey]uint64
+	latencies map[routeKey]*latencyHistogram
+}
+
+/*
+=================================================================================
+Metrics Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// Metrics is middleware that records the number of requests, by method, route and status code, and a
+// histogram of request latency, by method and route, for MetricsHandler to expose. Routes come from
+// RoutePattern, which should return the pattern the router matched (e.g. "/users/{id}"). Without it, the
+// request path is used with ID-like segments (numbers, UUIDs and long hex strings) replaced by "{id}", and
+// only the first 100 such routes get their own label. Requests that got a 404 or 405, and so matched no
+// route, are labelled "other", as are any non-standard methods, so the number of series stays bounded.
+func (t *Tools) Metrics(next http.Handler) http.Handler {
+	registry := t.metricsRegistry()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := t.NewStatusRecorder(w)
+
+		next.ServeHTTP(rec, r)
+
+		route := otherRoute
+		if rec.StatusCode != http.StatusNotFound && rec.StatusCode != http.StatusMethodNotAllowed {
+			route = t.routeLabel(r)
+		}
+		registry.observe(routeKey{method: metricsMethod(r.Method), route: route}, rec.StatusCode, time.Since(start))
+	})
+}
+
+// MetricsHandler returns a handler that exposes the metrics recorded by Metrics in the Prometheus text
+// format, as http_requests_total and http_request_duration_seconds, ready to be scraped.
+func (t *Tools) MetricsHandler() http.Handler {
+	registry := t.metricsRegistry()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(registry.render()))
+	})
+}
+
+// metricsRegistry returns the registry shared by Metrics and MetricsHandler, creating it on first use.
+func (t *Tools) metricsRegistry() *metricsRegistry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.metrics == nil {
+		t.metrics = &metricsRegistry{
+			requests:  make(map[requestKey]uint64),
+			latencies: make(map[routeKey]*latencyHistogram),
+		}
+	}
+
+	return t.metrics
+}
+
+// observe records one request to route that got status and took d.
+func (m *metricsRegistry) observe(route routeKey, status int, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[requestKey{routeKey: route, status: status}]++
+
+	h, ok := m.latencies[route]
+	if !ok {
+		h = &latencyHistogram{buckets: make([]uint64, len(metricsBuckets))}
+		m.latencies[route] = h
+	}
+	seconds := d.Seconds()
+	for i, upper := range metricsBuckets {
+		if seconds <= upper {
+			h.buckets[i]++
+			break
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// render formats the registry in the Prometheus text format, with series sorted so the output is stable.
+func (m *metricsRegistry) render() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sb strings.Builder
+
+	requestKeys := make([]requestKey, 0, len(m.requests))
+	for key := range m.requests {
+		requestKeys = append(requestKeys, key)
+	}
+	sort.Slice(requestKeys, func(i, j int) bool {
+		a, b := requestKeys[i], requestKeys[j]
+		if a.routeKey != b.routeKey {
+			return routeKeyLess(a.routeKey, b.routeKey)
+		}
+		return a.status < b.status
+	})
+
+	sb.WriteString("# HELP http_requests_total Total number of HTTP requests.\n")
+	sb.WriteString("# TYPE http_requests_total counter\n")
+	for _, key := range requestKeys {
+		fmt.Fprintf(&sb, "http_requests_total{method=%s,route=%s,status=\"%d\"} %d\n",
+			metricsLabel(key.method), metricsLabel(key.route), key.status, m.requests[key])
+	}
+
+	routeKeys := make([]routeKey, 0, len(m.latencies))
+	for key := range m.latencies {
+		routeKeys = append(routeKeys, key)
+	}
+	sort.Slice(routeKeys, func(i, j int) bool { return routeKeyLess(routeKeys[i], routeKeys[j]) })
+
+	sb.WriteString("# HELP http_request_duration_seconds HTTP request latency in seconds.\n")
+	sb.WriteString("# TYPE http_request_duration_seconds histogram\n")
+	for _, key := range routeKeys {
+		h := m.latencies[key]
+		labels := fmt.Sprintf("method=%s,route=%s", metricsLabel(key.method), metricsLabel(key.route))
+
+		// Prometheus buckets are cumulative.
+		var cumulative uint64
+		for i, upper := range metricsBuckets {
+			cumulative += h.buckets[i]
+			fmt.Fprintf(&sb, "http_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, strconv.FormatFloat(upper, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(&sb, "http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.count)
+		fmt.Fprintf(&sb, "http_request_duration_seconds_sum{%s} %s\n", labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&sb, "http_request_duration_seconds_count{%s} %d\n", labels, h.count)
+	}
+
+	return sb.String()
+}
+
+// routeKeyLess orders route keys by route, then method.
+func routeKeyLess(a, b routeKey) bool {
+	if a.route != b.route {
+		return a.route < b.route
+	}
+	return a.method < b.method
+}
+
+// routeLabel returns the bounded route label for r: the result of RoutePattern if it is set, or else the
+// request path with ID-like segments replaced by "{id}", falling back to otherRoute once maxRouteLabels
+// distinct routes have been seen.
+func (t *Tools) routeLabel(r *http.Request) string {
+	if t.RoutePattern != nil {
+		if route := t.RoutePattern(r); route != "" {
+			return route
+		}
+		return otherRoute
+	}
+
+	segments := strings.Split(r.URL.Path, "/")
+	for i, segment := range segments {
+		if idSegment.MatchString(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	route := strings.Join(segments, "/")
+
+	t.mu.RLock()
+	known := t.routeLabels[route]
+	t.mu.RUnlock()
+	if known {
+		return route
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.routeLabels == nil {
+		t.routeLabels = make(map[string]bool)
+	}
+	if !t.routeLabels[route] && len(t.routeLabels) >= maxRouteLabels {
+		return otherRoute
+	}
+	t.routeLabels[route] = true
+
+	return route
+}
+
+// metricsMethod returns the method label for method, folding non-standard methods into otherMethod.
+func metricsMethod(method string) string {
+	if standardMethods[method] {
+		return method
+	}
+	return otherMethod
+}
+
+// metricsLabel quotes value for use as a Prometheus label value.
+func metricsLabel(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + replacer.Replace(value) + `"`
+}
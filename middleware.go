@@ -0,0 +1,473 @@
+package goMicroServiceUtils
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+=================================================================================
+Middleware Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// contextKey is the type of the keys this package stores values in request contexts under.
+type contextKey string
+
+// RequestIDContextKey is the request context key the RequestID middleware stores the request ID under.
+const RequestIDContextKey contextKey = "request_id"
+
+// RequestIDHeader is the header the request ID is read from, and echoed back in.
+const RequestIDHeader = "X-Request-ID"
+
+// CORSOptions configures the EnableCORS middleware.
+type CORSOptions struct {
+	AllowedOrigins   []string // origins allowed to make cross-origin requests, or "*" for any
+	AllowedMethods   []string // methods allowed in preflight requests (defaults to GET, POST, PUT, PATCH, DELETE, OPTIONS)
+	AllowedHeaders   []string // request headers allowed in preflight requests (defaults to Accept, Authorization, Content-Type)
+	AllowCredentials bool     // if set to true, allow cookies and auth headers on cross-origin requests
+	MaxAge           int      // how long, in seconds, browsers may cache a preflight response (zero omits the header)
+}
+
+// timeoutWriter guards the ResponseWriter handed to a handler wrapped by Timeout, so the handler and the
+// timeout can never both write a response. Headers are collected separately and only copied to the real
+// writer once the handler writes, so the two goroutines never share a header map.
+type timeoutWriter struct {
+	w *StatusRecorder
+	h http.Header
+
+	mu       sync.Mutex
+	timedOut bool
+}
+
+// Header returns the handler's own header map.
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.h
+}
+
+// WriteHeader copies the handler's headers to the real writer and sends the status, unless the request has
+// already timed out.
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.writeHeaderLocked(status)
+}
+
+// writeHeaderLocked does the work of WriteHeader; tw.mu must be held.
+func (tw *timeoutWriter) writeHeaderLocked(status int) {
+	if tw.timedOut || tw.w.Written() {
+		return
+	}
+
+	tw.copyHeaderLocked()
+	tw.w.WriteHeader(status)
+}
+
+// copyHeaderLocked copies the handler's headers to the real writer; tw.mu must be held.
+func (tw *timeoutWriter) copyHeaderLocked() {
+	dst := tw.w.Header()
+	for key, value := range tw.h {
+		dst[key] = value
+	}
+}
+
+// Write writes to the real writer, or returns http.ErrHandlerTimeout if the request has timed out.
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.writeHeaderLocked(http.StatusOK)
+
+	return tw.w.Write(b)
+}
+
+// FlushError flushes the real writer, so streaming handlers such as SSEWriter and StreamJSONArray work behind
+// Timeout. It returns http.ErrHandlerTimeout if the request has timed out, and an error wrapping
+// http.ErrNotSupported, without sending anything, if the real writer can't flush.
+func (tw *timeoutWriter) FlushError() error {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return http.ErrHandlerTimeout
+	}
+
+	if tw.w.Written() {
+		return tw.w.FlushError()
+	}
+
+	// A successful flush sends the status itself, so only the headers need copying beforehand. If the flush
+	// fails nothing has been sent, so they are taken back out again, leaving the real writer as it was.
+	tw.copyHeaderLocked()
+	err := tw.w.FlushError()
+	if err != nil && !tw.w.Written() {
+		for key := range tw.h {
+			tw.w.Header().Del(key)
+		}
+	}
+
+	return err
+}
+
+// Hijack takes over the connection of the real writer, or returns http.ErrHandlerTimeout if the request has
+// timed out.
+func (tw *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+
+	return tw.w.Hijack()
+}
+
+// Unwrap returns the StatusRecorder around the real writer, so http.ResponseController and loggerFromWriter
+// can reach what it wraps, such as the logger attached by WithLogger.
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.w
+}
+
+// SecureHeadersConfig configures the SecureHeaders middleware. Empty fields use the defaults given.
+type SecureHeadersConfig struct {
+	ContentSecurityPolicy string // Content-Security-Policy value (defaults to default-src 'self')
+	FrameOptions          string // X-Frame-Options value (defaults to DENY)
+	ReferrerPolicy        string // Referrer-Policy value (defaults to strict-origin-when-cross-origin)
+}
+
+/*
+=================================================================================
+Middleware Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// CORSMiddleware checks the CORS settings on Tools and returns middleware applying them. It returns an error
+// if the settings are invalid, e.g. a wildcard origin combined with AllowCredentials, which the CORS spec
+// forbids.
+func (t *Tools) CORSMiddleware() (func(http.Handler) http.Handler, error) {
+	opts := t.CORS
+
+	allowAll := false
+	for _, origin := range opts.AllowedOrigins {
+		if origin == "*" {
+			allowAll = true
+		}
+	}
+	if allowAll && opts.AllowCredentials {
+		return nil, errors.New("cors: a wildcard origin cannot be used when credentials are allowed")
+	}
+
+	methods := opts.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	}
+	headers := opts.AllowedHeaders
+	if len(headers) == 0 {
+		headers = []string{"Accept", "Authorization", "Content-Type"}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			// Requests without an Origin aren't cross-origin, so there's nothing to do.
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Origin")
+			allowed := allowAll
+			for _, o := range opts.AllowedOrigins {
+				if strings.EqualFold(o, origin) {
+					allowed = true
+				}
+			}
+
+			if allowed {
+				if allowAll {
+					w.Header().Set("Access-Control-Allow-Origin", "*")
+				} else {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+				}
+				if opts.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+			}
+
+			if !preflight {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Answer the preflight ourselves; a disallowed origin simply gets no Access-Control-* headers.
+			if allowed {
+				w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+				if opts.MaxAge > 0 {
+					w.Header().Set("Access-Control-Max-Age", strconv.Itoa(opts.MaxAge))
+				}
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}, nil
+}
+
+// EnableCORS is middleware that applies the CORS settings on Tools to next, answering preflight requests with
+// a 204. It panics if the settings are invalid, so misconfiguration is caught when routes are set up; use
+// CORSMiddleware to handle the error instead.
+func (t *Tools) EnableCORS(next http.Handler) http.Handler {
+	mw, err := t.CORSMiddleware()
+	if err != nil {
+		panic(err)
+	}
+
+	return mw(next)
+}
+
+// RequestID is middleware that gives every request an ID for tracing across services. An incoming X-Request-ID
+// header is reused if present, otherwise a new ID is generated. The ID is stored in the request context, where
+// RequestIDFromContext can read it, and echoed back in the X-Request-ID response header, which is also where
+// ErrorJSON picks it up to include in error payloads.
+func (t *Tools) RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+
+		// Only trust incoming IDs that are a sensible size and plain printable ASCII, since they end up in
+		// logs and response headers.
+		if !validRequestID(requestID) {
+			requestID = t.RandomString(20)
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+		ctx := context.WithValue(r.Context(), RequestIDContextKey, requestID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestIDFromContext returns the request ID stored by the RequestID middleware, or an empty string if
+// there isn't one.
+func (t *Tools) RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(RequestIDContextKey).(string)
+	return requestID
+}
+
+// validRequestID reports whether an incoming request ID is non-empty, at most 128 characters, and made up
+// only of printable ASCII.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > 128 {
+		return false
+	}
+
+	for _, c := range requestID {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// RecoverJSON is middleware that recovers from panics in next, logs the panic and stack trace, and sends a
+// 500 JSON error response, so clients always get a well-formed reply. The panic value is only included in
+// the response when Development is set, so internals are never leaked in production.
+func (t *Tools) RecoverJSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+
+			// http.ErrAbortHandler is the sanctioned way to abort a response, so let the server deal with it.
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+
+			log.Printf("panic serving %s %s: %v\n%s", r.Method, r.URL.Path, p, debug.Stack())
+
+			err := errors.New("internal server error")
+			if t.Development {
+				err = fmt.Errorf("internal server error: %v", p)
+			}
+
+			w.Header().Set("Connection", "close")
+			_ = t.ErrorJSON(w, err, http.StatusInternalServerError)
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Timeout returns middleware that gives each request a deadline of d, via the request context. If the handler
+// hasn't started writing its response by then, a 503 JSON error is sent instead and anything the handler
+// writes afterwards is discarded. Handlers should watch r.Context() so they stop doing work once it is done.
+// The handler's writer is a StatusRecorder behind a guard, so flushing and hijacking still reach the real
+// writer until the deadline passes, after which they return http.ErrHandlerTimeout.
+func (t *Tools) Timeout(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			tw := &timeoutWriter{w: t.NewStatusRecorder(w), h: make(http.Header)}
+			done := make(chan struct{})
+			panicChan := make(chan interface{}, 1)
+
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicChan <- p
+					}
+				}()
+				next.ServeHTTP(tw, r.WithContext(ctx))
+				close(done)
+			}()
+
+			select {
+			case p := <-panicChan:
+				panic(p)
+
+			case <-done:
+				return
+
+			case <-ctx.Done():
+				tw.mu.Lock()
+				if tw.w.Written() {
+					// The handler is already part way through its response, so let it finish rather than
+					// corrupting what it has sent.
+					tw.mu.Unlock()
+					select {
+					case p := <-panicChan:
+						panic(p)
+					case <-done:
+					}
+					return
+				}
+				tw.timedOut = true
+				tw.mu.Unlock()
+
+				_ = t.ErrorJSON(w, errors.New("the request timed out"), http.StatusServiceUnavailable)
+			}
+		})
+	}
+}
+
+// SecureHeaders is middleware that sets OWASP-recommended security headers on every response, tuned by
+// SecureHeadersConfig on Tools. A header that is already set, e.g. by an outer middleware, is left alone,
+// and handlers can override any of them by setting the header themselves.
+func (t *Tools) SecureHeaders(next http.Handler) http.Handler {
+	csp := t.SecureHeadersConfig.ContentSecurityPolicy
+	if csp == "" {
+		csp = "default-src 'self'"
+	}
+	frameOptions := t.SecureHeadersConfig.FrameOptions
+	if frameOptions == "" {
+		frameOptions = "DENY"
+	}
+	referrerPolicy := t.SecureHeadersConfig.ReferrerPolicy
+	if referrerPolicy == "" {
+		referrerPolicy = "strict-origin-when-cross-origin"
+	}
+
+	headers := [][2]string{
+		{"X-Content-Type-Options", "nosniff"},
+		{"X-Frame-Options", frameOptions},
+		{"Content-Security-Policy", csp},
+		{"Referrer-Policy", referrerPolicy},
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, header := range headers {
+			if w.Header().Get(header[0]) == "" {
+				w.Header().Set(header[0], header[1])
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Chain composes middlewares into a single middleware, applied in the order listed, so the first is the
+// outermost and sees each request first:
+//
+//	handler := t.Chain(t.RecoverJSON, t.RequestID, t.LogRequests(logger))(mux)
+func (t *Tools) Chain(middlewares ...func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
+
+// RequireContentType returns middleware that rejects requests whose Content-Type isn't one of types with a
+// 415 JSON error, before the handler runs. Parameters such as charset are ignored when comparing. GET, HEAD,
+// DELETE and OPTIONS requests without a body don't need a Content-Type, so they are let through.
+func (t *Tools) RequireContentType(types ...string) func(http.Handler) http.Handler {
+	allowed := make(map[string]bool, len(types))
+	for _, contentType := range types {
+		allowed[strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))] = true
+	}
+	message := fmt.Sprintf("the Content-Type header must be one of: %s", strings.Join(types, ", "))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions:
+				if r.ContentLength == 0 {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			contentType := strings.ToLower(strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0]))
+			if !allowed[contentType] {
+				_ = t.ErrorJSON(w, errors.New(message), http.StatusUnsupportedMediaType)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// LimitBody returns middleware that limits every request body to maxBytes, whatever its content type and
+// whichever Read method (if any) the handler uses, as a backstop to the per-format limits such as
+// MaxJSONSize. Requests that declare a larger Content-Length are rejected with a 413 JSON error straight
+// away; for the rest, reading past the limit fails.
+func (t *Tools) LimitBody(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxBytes {
+				_ = t.ErrorJSON(w, t.bodyTooLarge(r, int(maxBytes)), http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			if r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package goMicroServiceUtils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+/*
+=================================================================================
+MessagePack Request/Response Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// ReadMsgPack tries to read the body of a request and converts it from MessagePack to a variable. The third
+// parameter, data, is expected to be a pointer, so that we can read data into it. Struct fields are matched
+// using their `json` tags, so the same types can be shared with the JSON helpers.
+func (t *Tools) ReadMsgPack(w http.ResponseWriter, r *http.Request, data interface{}) error {
+
+	// Check content-type header; it should be application/msgpack. If it's not specified,
+	// try to decode the body anyway.
+	if r.Header.Get("Content-Type") != "" {
+		contentType := strings.ToLower(strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0]))
+		if contentType != "application/msgpack" && contentType != "application/x-msgpack" {
+			return errors.New("the Content-Type header is not application/msgpack")
+		}
+	}
+
+	// Set a sensible default for the maximum payload size.
+	maxBytes := 1024 * 1024 // one megabyte
+
+	// If MaxMsgPackSize is set, use that value instead of default.
+	if t.MaxMsgPackSize != 0 {
+		maxBytes = t.MaxMsgPackSize
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, int64(maxBytes))
+
+	dec := msgpack.NewDecoder(r.Body)
+	dec.SetCustomStructTag("json")
+
+	// Should we allow unknown fields?
+	if !t.AllowUnknownFields {
+		dec.DisallowUnknownFields(true)
+	}
+
+	// The decoder reports a truncated value as io.EOF too, so check for an empty body up front.
+	_, err := dec.PeekCode()
+	if errors.Is(err, io.EOF) {
+		return errors.New("body must not be empty")
+	}
+
+	// Attempt to decode the data, and figure out what the error is, if any, to send back a human-readable
+	// response.
+	err = dec.Decode(data)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "http: request body too large"):
+			return t.bodyTooLarge(r, maxBytes)
+
+		case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+			return errors.New("body contains badly-formed MessagePack")
+
+		case strings.Contains(err.Error(), "unknown field"):
+			return fmt.Errorf("body contains %s", strings.TrimPrefix(err.Error(), "msgpack: "))
+
+		default:
+			return fmt.Errorf("body contains badly-formed MessagePack: %s", strings.TrimPrefix(err.Error(), "msgpack: "))
+		}
+	}
+
+	err = dec.Skip()
+	if !errors.Is(err, io.EOF) {
+		return errors.New("body must only contain a single MessagePack value")
+	}
+
+	return nil
+}
+
+// WriteMsgPack takes a response status code and arbitrary data and writes a MessagePack response to the client.
+// Struct fields are named using their `json` tags, as with ReadMsgPack.
+func (t *Tools) WriteMsgPack(w http.ResponseWriter, status int, data interface{}, headers ...http.Header) error {
+	var out bytes.Buffer
+	enc := msgpack.NewEncoder(&out)
+	enc.SetCustomStructTag("json")
+
+	err := enc.Encode(data)
+	if err != nil {
+		return err
+	}
+
+	// If we have a value as the last parameter in the function call, then we are setting a custom header.
+	if len(headers) > 0 {
+		for key, value := range headers[0] {
+			w.Header()[key] = value
+		}
+	}
+
+	// Set the content type and send response.
+	w.Header().Set("Content-Type", "application/msgpack")
+	w.WriteHeader(status)
+	_, _ = w.Write(out.Bytes())
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package goMicroServiceUtils
+
+import (
+	"reflect"
+	"strings"
+)
+
+/*
+=================================================================================
+Normalization Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// normalizeStrings trims leading and trailing whitespace from every string reachable from v, which ReadJSON
+// calls on the decoded value when NormalizeStrings is set. It follows pointers and recurses into struct
+// fields, slices, arrays and map values, but never changes map keys. Struct fields tagged
+// `normalize:"preserve"`, such as passwords, are left exactly as they were sent.
+func normalizeStrings(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			elem := v.Elem()
+			if v.Kind() == reflect.Interface && elem.Kind() == reflect.String {
+				// Strings held in an interface can't be set in place, so replace the whole value.
+				if v.CanSet() {
+					v.Set(reflect.ValueOf(strings.TrimSpace(elem.String())).Convert(elem.Type()))
+				}
+				return
+			}
+			normalizeStrings(elem)
+		}
+
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(strings.TrimSpace(v.String()))
+		}
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			sf := v.Type().Field(i)
+			if !sf.IsExported() || sf.Tag.Get("normalize") == "preserve" {
+				continue
+			}
+			normalizeStrings(v.Field(i))
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			normalizeStrings(v.Index(i))
+		}
+
+	case reflect.Map:
+		// Map values aren't addressable, so normalize a copy of each and store it back under the same key.
+		iter := v.MapRange()
+		for iter.Next() {
+			value := reflect.New(iter.Value().Type()).Elem()
+			value.Set(iter.Value())
+			normalizeStrings(value)
+			v.SetMapIndex(iter.Key(), value)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package goMicroServiceUtils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+/*
+=================================================================================
+Pagination Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// Pagination is the metadata sent alongside a page of results from a list endpoint.
+type Pagination struct {
+	CurrentPage  int  `json:"current_page"`
+	PageSize     int  `json:"page_size"`
+	TotalRecords int  `json:"total_records"`
+	TotalPages   int  `json:"total_pages"`
+	HasNext      bool `json:"has_next"`
+	HasPrev      bool `json:"has_prev"`
+}
+
+// SortField is one field from a sort query parameter, as returned by ReadSort.
+type SortField struct {
+	Field string // the field to sort by, always one of the allowed fields
+	Desc  bool   // true when the field was prefixed with "-", for descending order
+}
+
+/*
+=================================================================================
+Pagination Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// newPagination works out the pagination metadata for a page of pageSize records, out of total, with page
+// clamped so it always lies within the available pages.
+func newPagination(page, pageSize, total int) Pagination {
+	if pageSize < 1 {
+		pageSize = 1
+	}
+	if total < 0 {
+		total = 0
+	}
+
+	// Ceiling division, so a partial last page still counts as a page.
+	totalPages := (total + pageSize - 1) / pageSize
+
+	if page > totalPages {
+		page = totalPages
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	return Pagination{
+		CurrentPage:  page,
+		PageSize:     pageSize,
+		TotalRecords: total,
+		TotalPages:   totalPages,
+		HasNext:      page < totalPages,
+		HasPrev:      page > 1,
+	}
+}
+
+// WriteJSONPaginated writes a JSON response with items in the Data field and the pagination metadata for
+// the page in the Meta field.
+func (t *Tools) WriteJSONPaginated(w http.ResponseWriter, status int, items interface{}, page, pageSize, total int) error {
+	meta := newPagination(page, pageSize, total)
+
+	var payload JSONResponse
+	payload.Data = items
+	payload.Meta = &meta
+
+	return t.WriteJSON(w, status, payload)
+}
+
+// ReadPagination reads the page and page_size query parameters from a request. The page defaults to 1 and the
+// size to defaultSize, and the size is clamped to maxSize. An error is returned if either parameter is not a
+// positive whole number.
+func (t *Tools) ReadPagination(r *http.Request, defaultSize, maxSize int) (page, size int, err error) {
+	query := r.URL.Query()
+
+	page = 1
+	if raw := query.Get("page"); raw != "" {
+		page, err = strconv.Atoi(raw)
+		if err != nil || page < 1 {
+			return 0, 0, errors.New("page must be a positive whole number")
+		}
+	}
+
+	size = defaultSize
+	if raw := query.Get("page_size"); raw != "" {
+		size, err = strconv.Atoi(raw)
+		if err != nil || size < 1 {
+			return 0, 0, errors.New("page_size must be a positive whole number")
+		}
+	}
+
+	if maxSize > 0 && size > maxSize {
+		size = maxSize
+	}
+
+	return page, size, nil
+}
+
+// ReadSort reads the sort query parameter from a request, a comma-separated list of fields in priority order,
+// each optionally prefixed with "-" for descending or "+" for ascending order (e.g. sort=-created_at,name).
+// Every field must appear in allowed, so the result is safe to use as column names in a query; otherwise an
+// error naming the bad field is returned. No sort parameter returns no fields.
+func (t *Tools) ReadSort(r *http.Request, allowed []string) ([]SortField, error) {
+	raw := r.URL.Query().Get("sort")
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	isAllowed := make(map[string]bool, len(allowed))
+	for _, field := range allowed {
+		isAllowed[field] = true
+	}
+
+	var fields []SortField
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		var sf SortField
+		switch part[0] {
+		case '-':
+			sf.Desc = true
+			part = part[1:]
+		case '+':
+			part = part[1:]
+		}
+		sf.Field = part
+
+		if !isAllowed[sf.Field] {
+			return nil, fmt.Errorf("cannot sort by %q, allowed fields are: %s", sf.Field, strings.Join(allowed, ", "))
+		}
+		if seen[sf.Field] {
+			return nil, fmt.Errorf("cannot sort by %q more than once", sf.Field)
+		}
+		seen[sf.Field] = true
+
+		fields = append(fields, sf)
+	}
+
+	return fields, nil
+}
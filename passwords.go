@@ -0,0 +1,176 @@
+package goMicroServiceUtils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+/*
+=================================================================================
+Password Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// minPasswordLength is the shortest password PasswordStrength accepts without complaint.
+const minPasswordLength = 8
+
+// defaultBcryptCost is the bcrypt cost HashPassword uses when BcryptCost isn't set. It is a little above
+// bcrypt.DefaultCost, which is getting cheap on modern hardware.
+const defaultBcryptCost = 12
+
+// defaultCommonPasswords is the blacklist PasswordStrength uses until SetCommonPasswords is called. It is only
+// a handful of the most used passwords; services should load a proper list.
+var defaultCommonPasswords = []string{
+	"123456", "123456789", "12345678", "password", "qwerty", "qwerty123", "111111", "12345", "123123",
+	"1234567890", "1234567", "abc123", "password1", "password123", "iloveyou", "admin", "welcome",
+	"letmein", "monkey", "dragon", "football", "baseball", "sunshine", "princess", "trustno1",
+}
+
+/*
+=================================================================================
+Password Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// SetCommonPasswords replaces the blacklist of common passwords used by PasswordStrength. Matching ignores
+// case and surrounding whitespace.
+func (t *Tools) SetCommonPasswords(passwords []string) {
+	common := make(map[string]bool, len(passwords))
+	for _, password := range passwords {
+		common[strings.ToLower(strings.TrimSpace(password))] = true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.commonPasswords = common
+}
+
+// PasswordStrength scores pw from 0 (unusable) to 4 (strong), and lists the problems with it in a form that
+// can be shown to the user, so a signup form can say what to fix rather than just rejecting the password.
+// Passwords get a point for being at least 8 characters, another for at least 12, one for using three of
+// lowercase, uppercase, digits and symbols, and one for using all four. A common password always scores 0.
+func (t *Tools) PasswordStrength(pw string) (score int, issues []string) {
+	if t.isCommonPassword(pw) {
+		return 0, []string{"this password is too common, choose something harder to guess"}
+	}
+
+	length := utf8.RuneCountInString(pw)
+	if length >= minPasswordLength {
+		score++
+	} else {
+		issues = append(issues, "must be at least 8 characters long")
+	}
+	if length >= 12 {
+		score++
+	}
+
+	var hasLower, hasUpper, hasDigit, hasSymbol bool
+	for _, r := range pw {
+		switch {
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	classes := 0
+	for _, class := range []struct {
+		present bool
+		issue   string
+	}{
+		{hasLower, "should include a lowercase letter"},
+		{hasUpper, "should include an uppercase letter"},
+		{hasDigit, "should include a digit"},
+		{hasSymbol, "should include a symbol"},
+	} {
+		if class.present {
+			classes++
+		} else {
+			issues = append(issues, class.issue)
+		}
+	}
+	if classes >= 3 {
+		score++
+	}
+	if classes == 4 {
+		score++
+	}
+
+	// A password that is too short is weak however varied it is.
+	if length < minPasswordLength && score > 1 {
+		score = 1
+	}
+
+	return score, issues
+}
+
+// isCommonPassword reports whether pw is on the common password blacklist.
+func (t *Tools) isCommonPassword(pw string) bool {
+	pw = strings.ToLower(strings.TrimSpace(pw))
+
+	t.mu.RLock()
+	common := t.commonPasswords
+	t.mu.RUnlock()
+
+	if common == nil {
+		for _, password := range defaultCommonPasswords {
+			if password == pw {
+				return true
+			}
+		}
+		return false
+	}
+
+	return common[pw]
+}
+
+// HashPassword returns a bcrypt hash of pw, using BcryptCost (12 by default). Passwords longer than 72 bytes
+// are rejected, since bcrypt would silently ignore the rest.
+func (t *Tools) HashPassword(pw string) (string, error) {
+	cost := defaultBcryptCost
+	if t.BcryptCost != 0 {
+		cost = t.BcryptCost
+	}
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return "", fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(pw), cost)
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			return "", errors.New("password must not be longer than 72 bytes")
+		}
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// CheckPassword reports whether pw matches hash, a hash made by HashPassword. A wrong password returns false
+// and no error; errors are kept for hashes that are malformed.
+func (t *Tools) CheckPassword(pw, hash string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(pw))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return false, fmt.Errorf("invalid password hash: %s", err.Error())
+	}
+
+	return true, nil
+}
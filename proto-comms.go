@@ -0,0 +1,82 @@
+package goMicroServiceUtils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+)
+
+/*
+=================================================================================
+Protobuf Request/Response Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// ReadProto tries to read the body of a request and unmarshal it, as binary Protocol Buffers, into msg.
+func (t *Tools) ReadProto(r *http.Request, msg proto.Message) error {
+
+	// Check content-type header; it should be application/x-protobuf. If it's not specified,
+	// try to decode the body anyway.
+	if r.Header.Get("Content-Type") != "" {
+		contentType := strings.ToLower(strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0]))
+		if contentType != "application/x-protobuf" && contentType != "application/protobuf" {
+			return errors.New("the Content-Type header is not application/x-protobuf")
+		}
+	}
+
+	// Set a sensible default for the maximum payload size.
+	maxBytes := 1024 * 1024 // one megabyte
+
+	// If MaxProtoSize is set, use that value instead of default.
+	if t.MaxProtoSize != 0 {
+		maxBytes = t.MaxProtoSize
+	}
+	r.Body = http.MaxBytesReader(nil, r.Body, int64(maxBytes))
+
+	// Protobuf has no framing of its own, so the whole body has to be read before it can be unmarshalled.
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		if err.Error() == "http: request body too large" {
+			return t.bodyTooLarge(r, maxBytes)
+		}
+		return err
+	}
+
+	// Protobuf errors don't say what was being decoded, so name the expected message type.
+	err = proto.Unmarshal(body, msg)
+	if err != nil {
+		messageName := msg.ProtoReflect().Descriptor().FullName()
+		return fmt.Errorf("body is not a valid %s message: %s", messageName, strings.TrimSpace(strings.TrimPrefix(err.Error(), "proto:")))
+	}
+
+	return nil
+}
+
+// WriteProto takes a response status code and a Protocol Buffers message and writes it, in binary form, to the
+// client.
+func (t *Tools) WriteProto(w http.ResponseWriter, status int, msg proto.Message, headers ...http.Header) error {
+	out, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	// If we have a value as the last parameter in the function call, then we are setting a custom header.
+	if len(headers) > 0 {
+		for key, value := range headers[0] {
+			w.Header()[key] = value
+		}
+	}
+
+	// Set the content type and send response.
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(status)
+	_, _ = w.Write(out)
+
+	return nil
+}
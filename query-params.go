@@ -0,0 +1,70 @@
+package goMicroServiceUtils
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+)
+
+/*
+=================================================================================
+Query Parameter Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// ReadQuery reads a request's URL query parameters into data, which must be a pointer to a struct. Parameters
+// are matched to fields by their `query` struct tag, and fields without one are ignored. Strings, bools, ints,
+// uints, floats and time.Time (RFC 3339 or a plain date) are supported, as are pointers to those, which stay
+// nil when the parameter is missing, and slices of them for repeated parameters. Any values that can't be
+// converted are reported together as FieldErrors.
+func (t *Tools) ReadQuery(r *http.Request, data interface{}) error {
+	ptr := reflect.ValueOf(data)
+	if ptr.Kind() != reflect.Pointer || ptr.Elem().Kind() != reflect.Struct {
+		return errors.New("data must be a pointer to a struct")
+	}
+	elem := ptr.Elem()
+	query := r.URL.Query()
+
+	fieldErrors := FieldErrors{}
+	for i := 0; i < elem.NumField(); i++ {
+		sf := elem.Type().Field(i)
+		name, ok := sf.Tag.Lookup("query")
+		if !ok || name == "" || name == "-" || !sf.IsExported() {
+			continue
+		}
+
+		values, present := query[name]
+		if !present || len(values) == 0 {
+			continue
+		}
+
+		field := elem.Field(i)
+		if field.Kind() == reflect.Slice {
+			slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+			for j, value := range values {
+				err := setFieldFromString(slice.Index(j), value)
+				if err != nil {
+					fieldErrors[name] = err.Error()
+					break
+				}
+			}
+			if _, failed := fieldErrors[name]; !failed {
+				field.Set(slice)
+			}
+			continue
+		}
+
+		err := setFieldFromString(field, values[0])
+		if err != nil {
+			fieldErrors[name] = err.Error()
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return fieldErrors
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package goMicroServiceUtils
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/*
+=================================================================================
+Rate Limit Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// tokenBucket tracks the tokens available to a single client.
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// rateLimiter holds a token bucket per client, refilled at rps tokens per second up to burst.
+type rateLimiter struct {
+	rps   float64
+	burst float64
+	idle  time.Duration // how long a bucket must go unused before it is removed
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// RateLimitStore holds the request counters used by RateLimitWithStore. Implement it over a shared cache,
+// such as Redis with INCR and EXPIRE, to apply one limit across every instance of a service.
+type RateLimitStore interface {
+	// Incr adds one to the counter for key, creating it if need be, and returns the new count. A new counter
+	// should be kept for at least window, after which it may be removed.
+	Incr(key string, window time.Duration) (int, error)
+}
+
+// memoryRateLimitCounter is a counter held by memoryRateLimitStore, and when it expires.
+type memoryRateLimitCounter struct {
+	count     int
+	expiresAt time.Time
+}
+
+// memoryRateLimitStore is an in-memory RateLimitStore, suitable for a single instance of a service.
+type memoryRateLimitStore struct {
+	mu        sync.Mutex
+	counters  map[string]memoryRateLimitCounter
+	lastSweep time.Time
+}
+
+/*
+=================================================================================
+Rate Limit Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// RateLimit returns middleware that limits each client IP to rps requests per second, with bursts of up to
+// burst requests. Clients over the limit get a 429 JSON error with a Retry-After header. Clients are identified
+// with ClientIP, so proxies must be listed in TrustedProxies for X-Forwarded-For to be used. Buckets belonging
+// to clients that have gone quiet are removed at most once a minute, as requests arrive, so one-off clients
+// don't grow memory without bound and no background goroutine is left running.
+//
+// The buckets are held in memory, so each instance of a service has its own limit. Use RateLimitWithStore for
+// a limit shared between instances.
+func (t *Tools) RateLimit(rps float64, burst int) func(http.Handler) http.Handler {
+	if burst < 1 {
+		burst = 1
+	}
+
+	rl := &rateLimiter{
+		rps:     rps,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+	}
+
+	// A bucket idle long enough to have refilled completely is identical to a fresh one, so can be removed.
+	rl.idle = time.Minute
+	if rps > 0 {
+		refill := time.Duration(float64(burst) / rps * float64(time.Second))
+		if refill > rl.idle {
+			rl.idle = refill
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed, retryAfter := rl.allow(t.ClientIP(r), time.Now())
+			if !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				_ = t.ErrorJSON(w, errors.New("rate limit exceeded"), http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// allow takes a token from key's bucket if one is available. If not, it reports how long until one will be.
+func (rl *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if now.Sub(rl.lastSweep) > time.Minute {
+		rl.sweep(now)
+	}
+
+	b, exists := rl.buckets[key]
+	if !exists {
+		b = &tokenBucket{tokens: rl.burst, lastSeen: now}
+		rl.buckets[key] = b
+	}
+
+	// Refill for the time elapsed since the client was last seen, capped at the burst size.
+	b.tokens = math.Min(rl.burst, b.tokens+now.Sub(b.lastSeen).Seconds()*rl.rps)
+	b.lastSeen = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	if rl.rps <= 0 {
+		return false, time.Hour
+	}
+
+	return false, time.Duration((1 - b.tokens) / rl.rps * float64(time.Second))
+}
+
+// sweep removes buckets that have been idle for rl.idle; rl.mu must be held.
+func (rl *rateLimiter) sweep(now time.Time) {
+	for key, b := range rl.buckets {
+		if now.Sub(b.lastSeen) > rl.idle {
+			delete(rl.buckets, key)
+		}
+	}
+	rl.lastSweep = now
+}
+
+// RateLimitWithStore returns middleware that limits each client IP to limit requests per window on each
+// route, counting requests in store so the limit can be shared between instances. A nil store uses
+// NewMemoryRateLimitStore. Windows are fixed, starting at multiples of window since the Unix epoch, and the
+// current one is part of each key, along with ClientIP and the route, which is the same bounded label Metrics
+// uses: the result of RoutePattern if set, otherwise the collapsed path, with routes beyond the first 100
+// sharing one key. Clients over the limit get a 429 JSON error with a Retry-After header giving the
+// time until the next window. If store returns an error the request is let through, so an outage of a
+// shared cache doesn't take the service down with it.
+func (t *Tools) RateLimitWithStore(store RateLimitStore, limit int, window time.Duration) func(http.Handler) http.Handler {
+	if store == nil {
+		store = t.NewMemoryRateLimitStore()
+	}
+	if limit < 1 {
+		limit = 1
+	}
+	if window <= 0 {
+		window = time.Second
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			now := time.Now()
+			windowIndex := now.UnixNano() / int64(window)
+			key := "ratelimit " + t.routeLabel(r) + " " + t.ClientIP(r) + " " + strconv.FormatInt(windowIndex, 10)
+
+			count, err := store.Incr(key, window)
+			if err == nil && count > limit {
+				retryAfter := time.Unix(0, (windowIndex+1)*int64(window)).Sub(now)
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				_ = t.ErrorJSON(w, errors.New("rate limit exceeded"), http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// NewMemoryRateLimitStore returns a RateLimitStore that keeps counters in memory. Expired counters are
+// cleared out at most once a minute, as counters are incremented.
+func (t *Tools) NewMemoryRateLimitStore() RateLimitStore {
+	return &memoryRateLimitStore{counters: make(map[string]memoryRateLimitCounter)}
+}
+
+// Incr adds one to the unexpired counter for key, starting a new one that lasts for window if need be.
+func (s *memoryRateLimitStore) Incr(key string, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, c := range s.counters {
+			if now.After(c.expiresAt) {
+				delete(s.counters, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	c, ok := s.counters[key]
+	if !ok || now.After(c.expiresAt) {
+		c = memoryRateLimitCounter{expiresAt: now.Add(window)}
+	}
+	c.count++
+	s.counters[key] = c
+
+	return c.count, nil
+}
@@ -0,0 +1,45 @@
+package goMicroServiceUtils
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+/*
+=================================================================================
+Request Body Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// TeeBody arranges for everything read from the request body to also be written to sink, e.g. an audit log,
+// so the raw body can be captured while ReadJSON or another reader still consumes it as normal. Nothing is
+// buffered; bytes reach sink as they are read. The body is limited to MaxJSONSize, so at most that many
+// bytes are ever written to sink.
+func (t *Tools) TeeBody(r *http.Request, sink io.Writer) error {
+	if r.Body == nil {
+		return errors.New("request has no body")
+	}
+	if sink == nil {
+		return errors.New("sink must not be nil")
+	}
+
+	// Set a sensible default for the maximum payload size.
+	maxBytes := 1024 * 1024 // one megabyte
+
+	// If MaxJSONSize is set, use that value instead of default.
+	if t.MaxJSONSize != 0 {
+		maxBytes = t.MaxJSONSize
+	}
+
+	// Limit before teeing, so an oversized body fails the read rather than filling the sink.
+	limited := http.MaxBytesReader(nil, r.Body, int64(maxBytes))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.TeeReader(limited, sink), limited}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package goMicroServiceUtils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+/*
+=================================================================================
+Server Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// RunServer starts srv with ListenAndServe and blocks until the server fails or the process receives SIGINT
+// or SIGTERM. On a signal the server is shut down gracefully, giving in-flight requests up to shutdownTimeout
+// to finish. It returns any error from ListenAndServe other than http.ErrServerClosed, or from Shutdown.
+func (t *Tools) RunServer(srv *http.Server, shutdownTimeout time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+
+	case <-ctx.Done():
+	}
+
+	// Stop listening for signals, so a second one kills the process as normal.
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := srv.Shutdown(shutdownCtx)
+	if err != nil {
+		return err
+	}
+
+	err = <-serveErr
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
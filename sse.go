@@ -0,0 +1,133 @@
+package goMicroServiceUtils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+=================================================================================
+Server-Sent Events Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// SSEStream sends server-sent events to a client. Create one with Tools.SSEWriter. It is safe to use from
+// several goroutines at once.
+type SSEStream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+	mu sync.Mutex
+}
+
+// defaultKeepAliveInterval is how often KeepAlive sends a comment when it isn't given a positive interval.
+const defaultKeepAliveInterval = 15 * time.Second
+
+/*
+=================================================================================
+Server-Sent Events Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// SSEWriter sets the headers for a text/event-stream response on w and returns a stream to send events on.
+// It returns an error, without sending anything, if w can't be flushed, since events would otherwise sit in
+// a buffer. Writers wrapped by middleware are checked through their Unwrap method, with
+// http.ResponseController.
+func (t *Tools) SSEWriter(w http.ResponseWriter) (*SSEStream, error) {
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Stop proxies like nginx buffering the stream.
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	// Flushing sends the 200 status and headers straight away, and tells us whether the writer can stream.
+	err := rc.Flush()
+	if err != nil {
+		for _, key := range []string{"Content-Type", "Cache-Control", "Connection", "X-Accel-Buffering"} {
+			w.Header().Del(key)
+		}
+		return nil, errors.New("streaming is not supported by this response writer")
+	}
+
+	return &SSEStream{w: w, rc: rc}, nil
+}
+
+// Send JSON-encodes data and sends it as a single event, flushing it to the client straight away. If event is
+// empty, the event has no name and is delivered to the client's default message handler.
+func (s *SSEStream) Send(event string, data interface{}) error {
+	out, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if strings.ContainsAny(event, "\r\n") {
+		return errors.New("event name must not contain newlines")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if event != "" {
+		_, err = fmt.Fprintf(s.w, "event: %s\n", event)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(s.w, "data: %s\n\n", out)
+	if err != nil {
+		return err
+	}
+
+	return s.rc.Flush()
+}
+
+// Comment sends an SSE comment line, which clients ignore but which keeps idle connections alive.
+func (s *SSEStream) Comment(text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := fmt.Fprintf(s.w, ": %s\n\n", strings.NewReplacer("\r", " ", "\n", " ").Replace(text))
+	if err != nil {
+		return err
+	}
+
+	return s.rc.Flush()
+}
+
+// KeepAlive sends a keep-alive comment every interval, so proxies don't time out the connection while no
+// events are being sent, until ctx is done or a write fails. It returns immediately; the comments are sent
+// from a separate goroutine. Pass the request's context so sending stops when the client goes away. An
+// interval of zero or less uses the default of 15 seconds.
+func (s *SSEStream) KeepAlive(ctx context.Context, interval time.Duration) {
+	// Checked here rather than left to time.NewTicker, which would panic in a goroutine nothing can recover.
+	if interval <= 0 {
+		interval = defaultKeepAliveInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if s.Comment("keep-alive") != nil {
+					return
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,101 @@
+package goMicroServiceUtils
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+/*
+=================================================================================
+Status Recorder Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// StatusRecorder wraps an http.ResponseWriter and records the status code and number of body bytes sent
+// through it, for middleware such as logging, metrics and Timeout that need them once the handler has
+// finished. Flush and Hijack are passed through to the wrapped writer, and return an error through FlushError
+// and Hijack when it doesn't support them. Create one with Tools.NewStatusRecorder.
+type StatusRecorder struct {
+	http.ResponseWriter
+
+	StatusCode   int // the status sent, or http.StatusOK if the handler hasn't called WriteHeader
+	BytesWritten int // the number of body bytes written
+
+	wroteHeader bool
+}
+
+/*
+=================================================================================
+Status Recorder Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// NewStatusRecorder returns a StatusRecorder wrapping w.
+func (t *Tools) NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: w, StatusCode: http.StatusOK}
+}
+
+// Written reports whether the response status has been sent yet.
+func (sr *StatusRecorder) Written() bool {
+	return sr.wroteHeader
+}
+
+// WriteHeader records the status code before passing it on. As with the standard library, only the first
+// call has any effect.
+func (sr *StatusRecorder) WriteHeader(status int) {
+	if sr.wroteHeader {
+		return
+	}
+	sr.wroteHeader = true
+	sr.StatusCode = status
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+// Write counts the bytes written before passing them on, sending a 200 status first if none has been sent.
+func (sr *StatusRecorder) Write(b []byte) (int, error) {
+	if !sr.wroteHeader {
+		sr.WriteHeader(http.StatusOK)
+	}
+	n, err := sr.ResponseWriter.Write(b)
+	sr.BytesWritten += n
+	return n, err
+}
+
+// Flush sends any buffered data to the client, if the wrapped writer supports it. Use FlushError, or
+// http.NewResponseController, to find out whether it did.
+func (sr *StatusRecorder) Flush() {
+	_ = sr.FlushError()
+}
+
+// FlushError sends any buffered data to the client, returning an error wrapping http.ErrNotSupported if the
+// wrapped writer can't flush, so streaming handlers behind middleware fail rather than silently buffering.
+// http.ResponseController uses it in preference to Flush.
+func (sr *StatusRecorder) FlushError() error {
+	err := http.NewResponseController(sr.ResponseWriter).Flush()
+	if err != nil {
+		return err
+	}
+
+	// Flushing sends a 200 status if none has been sent yet.
+	if !sr.wroteHeader {
+		sr.wroteHeader = true
+		sr.StatusCode = http.StatusOK
+	}
+
+	return nil
+}
+
+// Hijack lets the caller take over the connection, e.g. for websockets, if the wrapped writer supports it.
+func (sr *StatusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(sr.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the wrapped writer, so http.ResponseController can reach any other features it has.
+func (sr *StatusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
@@ -0,0 +1,91 @@
+package goMicroServiceUtils
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+/*
+=================================================================================
+String Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// randomStringSource is the alphabet RandomString draws from.
+const randomStringSource = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// RandomString returns a string of n random characters drawn from randomStringSource, using crypto/rand so
+// the result is safe to use as a one-time token. A non-positive n returns an empty string.
+func (t *Tools) RandomString(n int) string {
+	if n <= 0 {
+		return ""
+	}
+
+	s := make([]byte, n)
+	max := big.NewInt(int64(len(randomStringSource)))
+	for i := range s {
+		// rand.Int picks uniformly from [0, max), so there is no modulo bias towards early characters.
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			// The system's secure random source failing is unrecoverable; don't hand out a weak token.
+			panic(err)
+		}
+		s[i] = randomStringSource[idx.Int64()]
+	}
+
+	return string(s)
+}
+
+// slugTransliterations maps common accented Latin characters to their closest ASCII equivalent so they
+// survive slugification rather than being stripped.
+var slugTransliterations = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae",
+	'ç': "c", 'è': "e", 'é': "e", 'ê': "e", 'ë': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ð': "d", 'ñ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'œ': "oe",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ý': "y", 'ÿ': "y",
+	'þ': "th", 'ß': "ss", 'ł': "l", 'ś': "s", 'š': "s", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// Slugify converts s into a URL-safe slug: lowercase ASCII letters and digits separated by single hyphens.
+// Common accented characters are transliterated and any other non-ASCII characters are stripped. An
+// error is returned if nothing is left once the string has been cleaned.
+func (t *Tools) Slugify(s string) (string, error) {
+	var b strings.Builder
+	pendingHyphen := false
+
+	for _, c := range strings.ToLower(s) {
+		var out string
+		switch {
+		case (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9'):
+			out = string(c)
+		case slugTransliterations[c] != "":
+			out = slugTransliterations[c]
+		case unicode.IsMark(c):
+			// Combining accents attached to the previous letter are dropped without splitting the word.
+			continue
+		default:
+			pendingHyphen = true
+			continue
+		}
+
+		// Collapse any run of separators into a single hyphen, never leading the slug.
+		if pendingHyphen && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		pendingHyphen = false
+		b.WriteString(out)
+	}
+
+	slug := b.String()
+	if len(slug) == 0 {
+		return "", errors.New("after removing characters, slug is zero length")
+	}
+
+	return slug, nil
+}
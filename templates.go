@@ -0,0 +1,112 @@
+package goMicroServiceUtils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"path/filepath"
+)
+
+/*
+=================================================================================
+Template Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// RenderTemplate executes the template called name from tmpl with data, and sends the result as an HTML
+// page. The template is rendered into a buffer first, so if it fails part way through the client gets
+// nothing rather than a half-written page, and the error is returned for the handler to deal with.
+func (t *Tools) RenderTemplate(w http.ResponseWriter, tmpl *template.Template, name string, data DisplayResponse) error {
+	if tmpl == nil {
+		return errors.New("template must not be nil")
+	}
+
+	var buf bytes.Buffer
+	err := tmpl.ExecuteTemplate(&buf, name, data)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, err = buf.WriteTo(w)
+
+	return err
+}
+
+// NewTemplateCache parses every page template in dir once, so handlers don't re-parse them on every request,
+// and returns them keyed by file name. Pages are the files named *.page.gohtml; each is parsed together with
+// all of the *.layout.gohtml and *.partial.gohtml files in dir, so pages can use shared layouts and partials.
+// Any parse error is returned, so a broken template is caught at startup.
+func (t *Tools) NewTemplateCache(dir string) (map[string]*template.Template, error) {
+	pages, err := filepath.Glob(filepath.Join(dir, "*.page.gohtml"))
+	if err != nil {
+		return nil, err
+	}
+
+	cache := make(map[string]*template.Template, len(pages))
+	for _, page := range pages {
+		tmpl, err := parseTemplatePage(dir, page)
+		if err != nil {
+			return nil, err
+		}
+		cache[filepath.Base(page)] = tmpl
+	}
+
+	t.mu.Lock()
+	t.templateDir = dir
+	t.mu.Unlock()
+
+	return cache, nil
+}
+
+// RenderCached renders the page called name (e.g. "home.page.gohtml") from a cache built by NewTemplateCache,
+// in the same way as RenderTemplate. When TemplateReload is set the page is re-parsed from disk first, so
+// template edits show up without restarting the service.
+func (t *Tools) RenderCached(w http.ResponseWriter, cache map[string]*template.Template, name string, data DisplayResponse) error {
+	tmpl, ok := cache[name]
+	if !ok {
+		return fmt.Errorf("template %q is not in the cache", name)
+	}
+
+	if t.TemplateReload {
+		t.mu.RLock()
+		dir := t.templateDir
+		t.mu.RUnlock()
+
+		var err error
+		tmpl, err = parseTemplatePage(dir, filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+	}
+
+	return t.RenderTemplate(w, tmpl, name, data)
+}
+
+// parseTemplatePage parses the page template at page along with the layouts and partials in dir.
+func parseTemplatePage(dir, page string) (*template.Template, error) {
+	tmpl, err := template.New(filepath.Base(page)).ParseFiles(page)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pattern := range []string{"*.layout.gohtml", "*.partial.gohtml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) > 0 {
+			tmpl, err = tmpl.ParseFiles(matches...)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return tmpl, nil
+}
@@ -0,0 +1,32 @@
+package goMicroServiceUtils
+
+import (
+	"io"
+	"net/http"
+)
+
+/*
+=================================================================================
+Text Response Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// WriteText sends text to the client as a plain text response, with the given status and, optionally, headers.
+// It is intended for the odd endpoint, such as robots.txt or a plain health check, that doesn't speak JSON.
+func (t *Tools) WriteText(w http.ResponseWriter, status int, text string, headers ...http.Header) error {
+	// If we have a value as the last parameter in the function call, then we are setting a custom header.
+	if len(headers) > 0 {
+		for key, value := range headers[0] {
+			w.Header()[key] = value
+		}
+	}
+
+	// Set the content type and send response.
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	_, err := io.WriteString(w, text)
+
+	return err
+}
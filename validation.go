@@ -0,0 +1,164 @@
+package goMicroServiceUtils
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+/*
+=================================================================================
+Validation Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// Validator accumulates field-level validation errors, so a handler can report every bad field at once.
+// Create one with Tools.NewValidator.
+type Validator struct {
+	Errors map[string]string // map of field name to error message
+}
+
+// FieldErrors maps field names to the problem found with each, and is returned by helpers such as ReadQuery
+// that check several fields at once.
+type FieldErrors map[string]string
+
+// Error implements the error interface, listing every field error in field order.
+func (fe FieldErrors) Error() string {
+	fields := make([]string, 0, len(fe))
+	for field := range fe {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	problems := make([]string, 0, len(fields))
+	for _, field := range fields {
+		problems = append(problems, fmt.Sprintf("%s: %s", field, fe[field]))
+	}
+
+	return strings.Join(problems, "; ")
+}
+
+// emailRX matches email addresses of the form almost everyone uses: a dot-atom local part, which allows
+// plus-addressing like user+tag@example.com, and a domain of at least two valid DNS labels.
+var emailRX = regexp.MustCompile("^[a-zA-Z0-9!#$%&'*+/=?^_`{|}~-]+(?:\\.[a-zA-Z0-9!#$%&'*+/=?^_`{|}~-]+)*@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)+$")
+
+// Validatable is implemented by request types that can check their own contents, for use with ReadAndValidate.
+type Validatable interface {
+	Validate() error
+}
+
+/*
+=================================================================================
+Validation Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// NewValidator returns a Validator with no errors.
+func (t *Tools) NewValidator() *Validator {
+	return &Validator{Errors: make(map[string]string)}
+}
+
+// Valid returns true if no errors have been recorded.
+func (v *Validator) Valid() bool {
+	return len(v.Errors) == 0
+}
+
+// AddError records message against field, unless field already has an error, in which case the first
+// message is kept.
+func (v *Validator) AddError(field, message string) {
+	if _, exists := v.Errors[field]; !exists {
+		v.Errors[field] = message
+	}
+}
+
+// Check records message against field if ok is false.
+func (v *Validator) Check(ok bool, field, message string) {
+	if !ok {
+		v.AddError(field, message)
+	}
+}
+
+// Required records an error against field if value is empty or only whitespace.
+func (v *Validator) Required(field, value string) {
+	v.Check(strings.TrimSpace(value) != "", field, "this field is required")
+}
+
+// Email records an error against field if value isn't a valid email address, as checked by
+// Tools.ValidEmail. Empty values are left to Required.
+func (v *Validator) Email(field, value string) {
+	if value != "" {
+		v.Check(validEmail(value), field, "must be a valid email address")
+	}
+}
+
+// ValidEmail reports whether s looks like a usable email address. It is deliberately looser than RFC 5322,
+// rejecting obvious garbage such as "foo@", "@bar" or addresses with spaces, while accepting anything a real
+// mail provider hands out, including plus-addressing like user+tag@example.com.
+func (t *Tools) ValidEmail(s string) bool {
+	return validEmail(s)
+}
+
+// validEmail does the work of ValidEmail, also enforcing the SMTP length limits of 64 characters for the
+// local part and 254 overall.
+func validEmail(s string) bool {
+	if len(s) > 254 {
+		return false
+	}
+	at := strings.LastIndex(s, "@")
+	if at < 1 || at > 64 {
+		return false
+	}
+	return emailRX.MatchString(s)
+}
+
+// FailedValidationJSON sends a 422 Unprocessable Entity JSON error response, with the validator's field
+// errors in the Data field of the payload.
+func (t *Tools) FailedValidationJSON(w http.ResponseWriter, v *Validator) error {
+	return t.ValidationErrorJSON(w, v.Errors)
+}
+
+// ValidationErrorJSON sends a 422 Unprocessable Entity JSON error response with the message "validation
+// failed", and errs, a map of field name to error message, in the Data field of the payload.
+func (t *Tools) ValidationErrorJSON(w http.ResponseWriter, errs map[string]string) error {
+	var payload JSONResponse
+	payload.Error = true
+	payload.Message = "validation failed"
+	payload.RequestID = w.Header().Get(RequestIDHeader)
+	payload.Data = errs
+
+	return t.WriteJSON(w, http.StatusUnprocessableEntity, payload)
+}
+
+// ReadAndValidate reads a JSON request body into a new T with ReadJSON, then calls its Validate method,
+// returning the populated value or the first error from either step. T may be a struct or a pointer to one;
+// for pointer types a new value is allocated to decode into.
+func ReadAndValidate[T Validatable](t *Tools, w http.ResponseWriter, r *http.Request) (T, error) {
+	var data T
+
+	var err error
+	if rt := reflect.TypeOf(data); rt != nil && rt.Kind() == reflect.Pointer {
+		data = reflect.New(rt.Elem()).Interface().(T)
+		err = t.ReadJSON(w, r, data)
+	} else {
+		err = t.ReadJSON(w, r, &data)
+	}
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	err = data.Validate()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,80 @@
+package goMicroServiceUtils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+/*
+=================================================================================
+Webhook Structures
+=================================================================================
+
+=================================================================================
+*/
+
+// ErrInvalidSignature is returned by VerifyWebhook when a request's signature is missing or doesn't match
+// its body.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+/*
+=================================================================================
+Webhook Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// VerifyWebhook checks that the request body was signed with secret, by comparing the HMAC-SHA256 of the raw
+// body against the hex-encoded signature in the headerName header (an optional "sha256=" prefix, as sent by
+// GitHub and others, is allowed). It returns the verified body, limited to MaxJSONSize, and leaves r.Body
+// holding a fresh copy of it, so ReadJSON can still be used afterwards. A missing or mismatched signature
+// returns an error wrapping ErrInvalidSignature.
+func (t *Tools) VerifyWebhook(r *http.Request, secret []byte, headerName string) ([]byte, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("a secret is required to verify a webhook")
+	}
+
+	signature := strings.TrimPrefix(strings.TrimSpace(r.Header.Get(headerName)), "sha256=")
+	if signature == "" {
+		return nil, fmt.Errorf("%w: missing %s header", ErrInvalidSignature, headerName)
+	}
+
+	// Set a sensible default for the maximum payload size.
+	maxBytes := 1024 * 1024 // one megabyte
+
+	// If MaxJSONSize is set, use that value instead of default.
+	if t.MaxJSONSize != 0 {
+		maxBytes = t.MaxJSONSize
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, int64(maxBytes)))
+	if err != nil {
+		if err.Error() == "http: request body too large" {
+			return nil, t.bodyTooLarge(r, maxBytes)
+		}
+		return nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, signHMAC(body, secret)) {
+		return nil, ErrInvalidSignature
+	}
+
+	return body, nil
+}
+
+// signHMAC returns the HMAC-SHA256 of body using secret.
+func signHMAC(body, secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,130 @@
+package goMicroServiceUtils
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+/*
+=================================================================================
+WebSocket Structures
+=================================================================================
+
+=================================================================================
+*/
+
+const (
+	wsWriteWait  = 10 * time.Second    // time allowed to write a message to the peer
+	wsPongWait   = 60 * time.Second    // time allowed to read the next pong from the peer
+	wsPingPeriod = wsPongWait * 9 / 10 // how often pings are sent, which must be less than wsPongWait
+)
+
+// JSONConn is a WebSocket connection that sends and receives JSON messages. Create one with Tools.UpgradeJSON.
+// Pings are sent automatically, and a peer that stops answering them is treated as gone, so reads fail and
+// the connection can be cleaned up. One goroutine may read while others write.
+type JSONConn struct {
+	conn *websocket.Conn
+
+	writeMu   sync.Mutex
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+/*
+=================================================================================
+WebSocket Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// UpgradeJSON upgrades an HTTP request to a WebSocket connection for exchanging JSON messages. Incoming
+// messages are limited to MaxJSONSize. Cross-origin upgrades are rejected unless the origin matches the host.
+// The caller must Close the connection when done with it.
+func (t *Tools) UpgradeJSON(w http.ResponseWriter, r *http.Request) (*JSONConn, error) {
+	// Set a sensible default for the maximum payload size.
+	maxBytes := 1024 * 1024 // one megabyte
+
+	// If MaxJSONSize is set, use that value instead of default.
+	if t.MaxJSONSize != 0 {
+		maxBytes = t.MaxJSONSize
+	}
+
+	var upgrader websocket.Upgrader
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetReadLimit(int64(maxBytes))
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	c := &JSONConn{conn: conn, done: make(chan struct{})}
+	go c.pingLoop()
+
+	return c, nil
+}
+
+// ReadJSON reads the next message from the peer and decodes it as JSON into v. Pongs are handled while
+// waiting, and an error is returned if the peer stops answering pings or sends a message over the size limit.
+func (c *JSONConn) ReadJSON(v interface{}) error {
+	err := c.conn.ReadJSON(v)
+	if errors.Is(err, websocket.ErrReadLimit) {
+		return errors.New("message is larger than the maximum allowed size")
+	}
+	return err
+}
+
+// WriteJSON encodes v as JSON and sends it to the peer as a single text message.
+func (c *JSONConn) WriteJSON(v interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return c.conn.WriteJSON(v)
+}
+
+// Close sends a close message to the peer, if it is still there, stops sending pings, and closes the
+// underlying connection.
+func (c *JSONConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+
+		c.writeMu.Lock()
+		_ = c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(wsWriteWait))
+		c.writeMu.Unlock()
+
+		err = c.conn.Close()
+	})
+	return err
+}
+
+// pingLoop pings the peer every wsPingPeriod until the connection is closed or a ping can't be sent.
+func (c *JSONConn) pingLoop() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.writeMu.Lock()
+			err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait))
+			c.writeMu.Unlock()
+			if err != nil {
+				// The peer is gone; closing the connection unblocks any pending read.
+				_ = c.conn.Close()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package goMicroServiceUtils
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+/*
+=================================================================================
+XML Request/Response Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// ReadXML tries to read the body of a request and converts it from XML to a variable. The third parameter, data,
+// is expected to be a pointer, so that we can read data into it.
+func (t *Tools) ReadXML(w http.ResponseWriter, r *http.Request, data interface{}) error {
+
+	// Check content-type header; it should be application/xml or text/xml. If it's not specified,
+	// try to decode the body anyway.
+	if r.Header.Get("Content-Type") != "" {
+		contentType := strings.ToLower(strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0]))
+		if contentType != "application/xml" && contentType != "text/xml" {
+			return errors.New("the Content-Type header is not application/xml or text/xml")
+		}
+	}
+
+	// Set a sensible default for the maximum payload size.
+	maxBytes := 1024 * 1024 // one megabyte
+
+	// If MaxXMLSize is set, use that value instead of default.
+	if t.MaxXMLSize != 0 {
+		maxBytes = t.MaxXMLSize
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, int64(maxBytes))
+
+	dec := xml.NewDecoder(r.Body)
+
+	// Attempt to decode the data, and figure out what the error is, if any, to send back a human-readable
+	// response.
+	err := dec.Decode(data)
+	if err != nil {
+		var syntaxError *xml.SyntaxError
+
+		switch {
+		case err.Error() == "http: request body too large":
+			return t.bodyTooLarge(r, maxBytes)
+
+		case errors.As(err, &syntaxError):
+			return fmt.Errorf("body contains badly-formed XML (at line %d): %s", syntaxError.Line, syntaxError.Msg)
+
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return errors.New("body contains badly-formed XML")
+
+		case errors.Is(err, io.EOF):
+			return errors.New("body must not be empty")
+
+		default:
+			return err
+		}
+	}
+
+	// Anything after the root element other than whitespace, comments or processing instructions
+	// means the body held more than one document.
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if err.Error() == "http: request body too large" {
+				return t.bodyTooLarge(r, maxBytes)
+			}
+			return errors.New("body must only contain a single XML root element")
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			return errors.New("body must only contain a single XML root element")
+		case xml.CharData:
+			if len(strings.TrimSpace(string(tok))) > 0 {
+				return errors.New("body must only contain a single XML root element")
+			}
+		}
+	}
+
+	return nil
+}
+
+// WriteXML takes a response status code and arbitrary data and writes an XML response to the client.
+func (t *Tools) WriteXML(w http.ResponseWriter, status int, data interface{}, headers ...http.Header) error {
+	out, err := xml.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	// If we have a value as the last parameter in the function call, then we are setting a custom header.
+	if len(headers) > 0 {
+		for key, value := range headers[0] {
+			w.Header()[key] = value
+		}
+	}
+
+	// Set the content type and send response, prefixed with the standard XML header so the output is a
+	// valid standalone document.
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(out)
+
+	return nil
+}
+
+// ErrorXML takes an error, and optionally a response status code, and generates and sends
+// an XML error response.
+func (t *Tools) ErrorXML(w http.ResponseWriter, err error, status ...int) error {
+	statusCode := http.StatusBadRequest
+
+	// If a custom response code is specified, use that instead of bad request.
+	if len(status) > 0 {
+		statusCode = status[0]
+	}
+
+	// Build the XML payload.
+	var payload XMLResponse
+	payload.Error = true
+	payload.Message = err.Error()
+
+	return t.WriteXML(w, statusCode, payload)
+}
+
+// WriteXMLNS behaves like WriteXML, but for picky clients such as SOAP services it lets the caller choose the
+// Content-Type (e.g. "text/xml"; "" means application/xml), the root element name (e.g. "Envelope"; "" keeps
+// the usual name for data's type) and a default namespace, sent as an xmlns attribute on the root element
+// ("" means none).
+func (t *Tools) WriteXMLNS(w http.ResponseWriter, status int, contentType, namespace, rootName string, data interface{}, headers ...http.Header) error {
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+
+	var err error
+	if rootName == "" && namespace == "" {
+		err = enc.Encode(data)
+	} else {
+		// Marshal once without a template to find the root element name data would normally have.
+		name := rootName
+		if name == "" {
+			var probe bytes.Buffer
+			err = xml.NewEncoder(&probe).Encode(data)
+			if err != nil {
+				return err
+			}
+			var root xml.StartElement
+			root, err = firstStartElement(probe.Bytes())
+			if err != nil {
+				return err
+			}
+			name = root.Name.Local
+		}
+		err = enc.EncodeElement(data, xml.StartElement{Name: xml.Name{Space: namespace, Local: name}})
+	}
+	if err != nil {
+		return err
+	}
+
+	// If we have a value as the last parameter in the function call, then we are setting a custom header.
+	if len(headers) > 0 {
+		for key, value := range headers[0] {
+			w.Header()[key] = value
+		}
+	}
+
+	if contentType == "" {
+		contentType = "application/xml"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(buf.Bytes())
+
+	return nil
+}
+
+// firstStartElement returns the root element of the XML document in doc.
+func firstStartElement(doc []byte) (xml.StartElement, error) {
+	dec := xml.NewDecoder(bytes.NewReader(doc))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start, nil
+		}
+	}
+}
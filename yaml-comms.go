@@ -0,0 +1,163 @@
+package goMicroServiceUtils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+/*
+=================================================================================
+YAML Request/Response Utils
+=================================================================================
+
+=================================================================================
+*/
+
+// yamlDuplicateKey matches the duplicate key errors produced by the yaml decoder.
+var yamlDuplicateKey = regexp.MustCompile(`line (\d+): mapping key "(.*)" already defined at line (\d+)`)
+
+// yamlTabIndent matches the errors the yaml decoder produces when it knows a line is indented with tabs.
+var yamlTabIndent = regexp.MustCompile(`(?:line (\d+): )?found a tab character (?:that violates indentation|where an indentation space is expected)`)
+
+// yamlBadToken matches the error the yaml decoder produces for a character that can't start a token, which
+// is what a tab at the start of a line usually gets, e.g. before a key or a list item.
+var yamlBadToken = regexp.MustCompile(`(?:line (\d+): )?found character that cannot start any token`)
+
+// ReadYAML tries to read the body of a request and converts it from YAML to a variable. The third parameter, data,
+// is expected to be a pointer, so that we can read data into it.
+func (t *Tools) ReadYAML(w http.ResponseWriter, r *http.Request, data interface{}) error {
+
+	// Check content-type header; it should be one of the YAML types. If it's not specified,
+	// try to decode the body anyway.
+	if r.Header.Get("Content-Type") != "" {
+		contentType := strings.ToLower(strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0]))
+		if contentType != "text/yaml" && contentType != "application/x-yaml" && contentType != "application/yaml" {
+			return errors.New("the Content-Type header is not text/yaml or application/x-yaml")
+		}
+	}
+
+	// Set a sensible default for the maximum payload size.
+	maxBytes := 1024 * 1024 // one megabyte
+
+	// If MaxYAMLSize is set, use that value instead of default.
+	if t.MaxYAMLSize != 0 {
+		maxBytes = t.MaxYAMLSize
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, int64(maxBytes))
+
+	// Keep what the decoder reads, so the line an error refers to can be inspected.
+	var seen bytes.Buffer
+	dec := yaml.NewDecoder(io.TeeReader(r.Body, &seen))
+
+	// Should we allow unknown fields?
+	if !t.AllowUnknownFields {
+		dec.KnownFields(true)
+	}
+
+	// Attempt to decode the data, and figure out what the error is, if any, to send back a human-readable
+	// response.
+	err := dec.Decode(data)
+	if err != nil {
+		var typeError *yaml.TypeError
+
+		// A character that can't start a token is only a tab problem if the line it's on is indented with one.
+		tabTokenLine := 0
+		if match := yamlBadToken.FindStringSubmatch(err.Error()); match != nil {
+			if line := yamlErrorLine(match[1]); yamlTabLine(seen.Bytes(), line) == line {
+				tabTokenLine = line
+			}
+		}
+
+		switch {
+		case errors.Is(err, io.EOF):
+			return errors.New("body must not be empty")
+
+		case strings.Contains(err.Error(), "http: request body too large"):
+			return t.bodyTooLarge(r, maxBytes)
+
+		case yamlTabIndent.MatchString(err.Error()):
+			// The decoder sometimes reports the line before the tab, so look for it from there.
+			line := yamlErrorLine(yamlTabIndent.FindStringSubmatch(err.Error())[1])
+			if tabLine := yamlTabLine(seen.Bytes(), line); tabLine != 0 {
+				line = tabLine
+			}
+			return fmt.Errorf("body is indented with tabs on line %d, YAML must be indented with spaces", line)
+
+		case tabTokenLine != 0:
+			return fmt.Errorf("body is indented with tabs on line %d, YAML must be indented with spaces", tabTokenLine)
+
+		case yamlDuplicateKey.MatchString(err.Error()):
+			match := yamlDuplicateKey.FindStringSubmatch(err.Error())
+			return fmt.Errorf("body contains duplicate key %q on line %s (first defined on line %s)", match[2], match[1], match[3])
+
+		case errors.As(err, &typeError):
+			return fmt.Errorf("body contains incorrect YAML: %s", strings.Join(typeError.Errors, "; "))
+
+		default:
+			return fmt.Errorf("body contains badly-formed YAML: %s", strings.TrimPrefix(err.Error(), "yaml: "))
+		}
+	}
+
+	var extra interface{}
+	err = dec.Decode(&extra)
+	if err != io.EOF {
+		return errors.New("body must only contain a single YAML document")
+	}
+
+	return nil
+}
+
+// yamlErrorLine converts the line number captured from a yaml decoder error to an int. Errors on the first
+// line carry no line number, so an empty string is line 1.
+func yamlErrorLine(captured string) int {
+	line, err := strconv.Atoi(captured)
+	if err != nil || line < 1 {
+		return 1
+	}
+	return line
+}
+
+// yamlTabLine returns the number of the first line of body, from line from onwards, whose indentation
+// contains a tab, or 0 if there isn't one.
+func yamlTabLine(body []byte, from int) int {
+	for i, line := range strings.Split(string(body), "\n") {
+		if i+1 < from {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if strings.Contains(indent, "\t") {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// WriteYAML takes a response status code and arbitrary data and writes a YAML response to the client.
+func (t *Tools) WriteYAML(w http.ResponseWriter, status int, data interface{}, headers ...http.Header) error {
+	out, err := yaml.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	// If we have a value as the last parameter in the function call, then we are setting a custom header.
+	if len(headers) > 0 {
+		for key, value := range headers[0] {
+			w.Header()[key] = value
+		}
+	}
+
+	// Set the content type and send response.
+	w.Header().Set("Content-Type", "application/x-yaml")
+	w.WriteHeader(status)
+	_, _ = w.Write(out)
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package goMicroServiceUtils
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTools_ReadYAMLTabs(t *testing.T) {
+	var tests = []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{name: "tab before key", body: "a:\n\tb: 1\n", wantErr: "body is indented with tabs on line 2, YAML must be indented with spaces"},
+		{name: "tab before list item", body: "a:\n\t- x\n\t- y\n", wantErr: "body is indented with tabs on line 2, YAML must be indented with spaces"},
+		{name: "tab after spaces in list", body: "list:\n  - x\n\t- y\n", wantErr: "body is indented with tabs on line 3, YAML must be indented with spaces"},
+		{name: "tab on first line", body: "\ta: 1\n", wantErr: "body is indented with tabs on line 1, YAML must be indented with spaces"},
+		{name: "tab inside quoted scalar", body: "a: \"foo\n\tbar\"\n"},
+		{name: "bad character without tab", body: "a: @x\n", wantErr: "body contains badly-formed YAML: found character that cannot start any token"},
+	}
+
+	for _, e := range tests {
+		var tools Tools
+		tools.AllowUnknownFields = true
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader(e.body))
+
+		var data interface{}
+		err := tools.ReadYAML(httptest.NewRecorder(), req, &data)
+
+		if e.wantErr == "" && err != nil {
+			t.Errorf("%s: unexpected error: %s", e.name, err.Error())
+		}
+		if e.wantErr != "" && (err == nil || err.Error() != e.wantErr) {
+			t.Errorf("%s: expected error %q, got %v", e.name, e.wantErr, err)
+		}
+	}
+}